@@ -0,0 +1,429 @@
+// Package analyzer defines an Analyzer that reports name maps generated by
+// mapconst that are missing or out of date.
+//
+// A generated file is out of date if a constant of one of its types is not
+// in it, typically one declared after the last run of go generate. Removed
+// or renamed constants need no analysis: they break the build. A file is
+// missing if a //go:generate directive running mapconst names it, or its
+// default name, and it does not exist; without -type, the default name is
+// that of the first type of the //mapconst:types= directives of the
+// package, or else of the const declaration following the directive.
+// Constants left out by -skip-deprecated are not expected in files
+// generated with it, nor, with -aliases=first, those of the same key as a
+// constant declared before them.
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/empirefox/mapconst/gen"
+	"golang.org/x/tools/go/analysis"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "mapconst",
+	Doc:       "report name maps generated by mapconst that are missing or out of date",
+	Run:       run,
+	FactTypes: []analysis.Fact{new(deprecated)},
+}
+
+// deprecated is the fact of a constant documented as deprecated by a
+// "Deprecated: " paragraph.
+type deprecated struct{}
+
+func (*deprecated) AFact() {}
+
+func (*deprecated) String() string { return "deprecated" }
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	exportDeprecated(pass)
+	for _, file := range pass.Files {
+		if args, ok := generatedArgs(file); ok {
+			checkGenerated(pass, file, args)
+		} else {
+			checkDirectives(pass, file)
+		}
+	}
+	return nil, nil
+}
+
+// exportDeprecated exports the deprecated fact of the deprecated constants
+// of the package, for the generated files of the packages importing it too.
+func exportDeprecated(pass *analysis.Pass) {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				vspec := spec.(*ast.ValueSpec)
+				if gen.ConstDeprecation(decl, vspec) == "" {
+					continue
+				}
+				for _, name := range vspec.Names {
+					if c, ok := pass.TypesInfo.Defs[name].(*types.Const); ok {
+						pass.ExportObjectFact(c, new(deprecated))
+					}
+				}
+			}
+		}
+	}
+}
+
+// generatedArgs returns the arguments of the command line in the "Code
+// generated" line of the file, and whether mapconst generated it.
+func generatedArgs(file *ast.File) ([]string, bool) {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if !strings.HasPrefix(c.Text, `// Code generated by "mapconst`) {
+				continue
+			}
+			line := strings.TrimPrefix(c.Text, `// Code generated by "mapconst`)
+			if i := strings.Index(line, `";`); i >= 0 {
+				line = line[:i]
+			}
+			return strings.Fields(line), true
+		}
+	}
+	return nil, false
+}
+
+// boolFlag reports whether the boolean flag is set in the arguments.
+func boolFlag(args []string, name string) bool {
+	set := false
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		switch strings.TrimLeft(arg, "-") {
+		case name, name + "=true":
+			set = true
+		case name + "=false":
+			set = false
+		}
+	}
+	return set
+}
+
+// stringFlag returns the value of the flag in the arguments, or "" if it
+// is not set. The values of a repeated -type accumulate, separated by ";".
+func stringFlag(args []string, name string) string {
+	value := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		var v string
+		switch flag := strings.TrimLeft(arg, "-"); {
+		case strings.HasPrefix(flag, name+"="):
+			v = strings.TrimPrefix(flag, name+"=")
+		case flag == name && i+1 < len(args):
+			i++
+			v = args[i]
+		default:
+			continue
+		}
+		if name == "type" && value != "" {
+			v = value + ";" + v
+		}
+		value = v
+	}
+	return value
+}
+
+// keyFunc returns the function returning the key of a constant of a type in
+// the lookups generated with the arguments.
+func keyFunc(args []string) func(typ types.Type, name string) (string, error) {
+	cfg := &gen.Config{
+		KeyPrefix: stringFlag(args, "keyprefix"),
+		KeySuffix: stringFlag(args, "keysuffix"),
+		Normalize: stringFlag(args, "normalize"),
+	}
+	trimPrefix, transform := stringFlag(args, "trimprefix"), stringFlag(args, "transform")
+	specs, _ := gen.ParseTypeSpecs(stringFlag(args, "type"), trimPrefix, transform)
+	return func(typ types.Type, name string) (string, error) {
+		typeName := types.TypeString(typ, func(*types.Package) string { return "" })
+		spec := gen.TypeSpec{Name: typeName, TrimPrefix: trimPrefix, Transform: transform}
+		for _, s := range specs {
+			if s.Name == typeName || strings.HasSuffix(s.Name, "."+typeName) {
+				spec = s
+				break
+			}
+		}
+		return gen.Key(name, spec, cfg)
+	}
+}
+
+// checkGenerated reports the constants of the types of the generated file
+// that it does not map a name to. The types are those of the constants it
+// does map; the candidates are declared in the same packages. The arguments
+// are those of the command that generated the file. With -aliases=first,
+// the constants of the same key as one declared before are not expected.
+func checkGenerated(pass *analysis.Pass, file *ast.File, args []string) {
+	skipDeprecated := boolFlag(args, "skip-deprecated")
+	aliasesFirst := stringFlag(args, "aliases") == "first"
+	key := keyFunc(args)
+	type typeUse struct {
+		typ  types.Type
+		pos  token.Pos               // First mapping of a constant of the type.
+		pkgs map[*types.Package]bool // Packages declaring the constants.
+		used map[*types.Const]bool   // The constants mapped.
+	}
+	var uses []*typeUse
+	useConst := func(c *types.Const, pos token.Pos) {
+		var use *typeUse
+		for _, u := range uses {
+			if types.Identical(u.typ, c.Type()) {
+				use = u
+				break
+			}
+		}
+		if use == nil {
+			use = &typeUse{
+				typ:  c.Type(),
+				pos:  pos,
+				pkgs: make(map[*types.Package]bool),
+				used: make(map[*types.Const]bool),
+			}
+			uses = append(uses, use)
+		}
+		use.pkgs[c.Pkg()] = true
+		use.used[c] = true
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		for _, expr := range mappedExprs(n) {
+			id, ok := ast.Unparen(expr).(*ast.Ident)
+			if sel, isSel := ast.Unparen(expr).(*ast.SelectorExpr); isSel {
+				id, ok = sel.Sel, true
+			}
+			if !ok {
+				continue
+			}
+			if c, ok := pass.TypesInfo.Uses[id].(*types.Const); ok && c.Pkg() != nil {
+				useConst(c, id.Pos())
+			}
+		}
+		return true
+	})
+
+	name := filepath.Base(pass.Fset.File(file.Pos()).Name())
+	for _, use := range uses {
+		// The constants mapconst generates, in declaration order.
+		var consts []*types.Const
+		for pkg := range use.pkgs {
+			scope := pkg.Scope()
+			for _, n := range scope.Names() {
+				c, ok := scope.Lookup(n).(*types.Const)
+				if !ok || c.Name() == "_" || !types.Identical(c.Type(), use.typ) {
+					continue
+				}
+				if pkg != pass.Pkg && !c.Exported() {
+					continue
+				}
+				// Constants of test files are never generated.
+				if pos := pass.Fset.File(c.Pos()); pos != nil && strings.HasSuffix(pos.Name(), "_test.go") {
+					continue
+				}
+				if skipDeprecated && pass.ImportObjectFact(c, new(deprecated)) {
+					continue
+				}
+				consts = append(consts, c)
+			}
+		}
+		sort.Slice(consts, func(i, j int) bool { return consts[i].Pos() < consts[j].Pos() })
+		seen := make(map[string]bool)
+		for _, c := range consts {
+			if aliasesFirst {
+				if k, err := key(use.typ, c.Name()); err == nil {
+					if seen[k] {
+						continue
+					}
+					seen[k] = true
+				}
+			}
+			if !use.used[c] {
+				pass.Reportf(use.pos, "%s is missing from %s; run go generate", c.Name(), name)
+			}
+		}
+	}
+}
+
+// mappedExprs returns the expressions of the constants that the node maps
+// names to. Every lookup pairs names with constants in one of four forms: a
+// map entry "Name": Name, a case "Name": return Name, true of a switch, a
+// table entry {"Name", Name}, or an array of the constants in the order of
+// names kept apart: the _T_sortedValues of the binarysearch lookup and the
+// _T_blobValues of maps filled from a blob.
+func mappedExprs(n ast.Node) []ast.Expr {
+	switch n := n.(type) {
+	case *ast.KeyValueExpr:
+		if isString(n.Key) {
+			return []ast.Expr{n.Value}
+		}
+	case *ast.CompositeLit:
+		if len(n.Elts) == 2 && isString(n.Elts[0]) {
+			return n.Elts[1:]
+		}
+	case *ast.CaseClause:
+		if len(n.List) == 1 && isString(n.List[0]) && len(n.Body) == 1 {
+			if ret, ok := n.Body[0].(*ast.ReturnStmt); ok && len(ret.Results) == 2 {
+				return ret.Results[:1]
+			}
+		}
+	case *ast.ValueSpec:
+		if len(n.Names) != 1 || len(n.Values) != 1 || !isValuesArray(n.Names[0].Name) {
+			break
+		}
+		if lit, ok := n.Values[0].(*ast.CompositeLit); ok {
+			return lit.Elts
+		}
+	}
+	return nil
+}
+
+// isValuesArray reports whether the variable name is that of an array of
+// constants in the order of their names: _T_sortedValues or _T_blobValues.
+func isValuesArray(name string) bool {
+	return strings.HasPrefix(name, "_") && (strings.HasSuffix(name, "_sortedValues") || strings.HasSuffix(name, "_blobValues"))
+}
+
+// isString reports whether the expression is a string literal.
+func isString(x ast.Expr) bool {
+	lit, ok := x.(*ast.BasicLit)
+	return ok && lit.Kind == token.STRING
+}
+
+// checkDirectives reports //go:generate directives running mapconst whose
+// output file does not exist.
+func checkDirectives(pass *analysis.Pass, file *ast.File) {
+	filename := pass.Fset.File(file.Pos()).Name()
+	dir := filepath.Dir(filename)
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			if !strings.HasPrefix(c.Text, "//go:generate ") {
+				continue
+			}
+			// Without -type, mapconst generates the types of the
+			// //mapconst:types= directives of the package, or else the
+			// type of the const declaration following the directive.
+			defaultType := func() (string, bool) {
+				if typ, ok := typesDirective(pass); ok {
+					return typ, true
+				}
+				typ, err := gen.ConstTypeAfter(filename, pass.Fset.Position(c.Pos()).Line)
+				return typ, err == nil
+			}
+			output, ok := directiveOutput(strings.Fields(strings.TrimPrefix(c.Text, "//go:generate ")), defaultType)
+			if !ok {
+				continue
+			}
+			if !filepath.IsAbs(output) {
+				output = filepath.Join(dir, output)
+			}
+			if _, err := os.Stat(output); os.IsNotExist(err) {
+				pass.Reportf(c.Pos(), "%s does not exist; run go generate", filepath.Base(output))
+			}
+		}
+	}
+}
+
+// typesDirective returns the first type of the //mapconst:types=
+// directives of the package, if any.
+func typesDirective(pass *analysis.Pass) (string, bool) {
+	for _, file := range pass.Files {
+		if _, ok := generatedArgs(file); ok {
+			continue
+		}
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				if !strings.HasPrefix(c.Text, "//mapconst:types=") {
+					continue
+				}
+				fields := strings.Fields(strings.TrimPrefix(c.Text, "//mapconst:types="))
+				if len(fields) > 0 {
+					return strings.Split(fields[0], ",")[0], true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// directiveOutput returns the Go file written by the command of a
+// //go:generate directive, relative to its directory, and whether the
+// command runs mapconst and writes a file. defaultType returns the type
+// generated without -type, and whether there is one.
+func directiveOutput(args []string, defaultType func() (string, bool)) (string, bool) {
+	// The command is either mapconst itself or go run of it.
+	switch {
+	case len(args) > 0 && isMapconst(args[0]):
+		args = args[1:]
+	case len(args) > 2 && args[0] == "go" && args[1] == "run" && isMapconst(args[2]):
+		args = args[3:]
+	default:
+		return "", false
+	}
+	flags := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		arg := strings.Trim(args[i], `"'`)
+		if !strings.HasPrefix(arg, "-") {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		value := ""
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value = name[:j], name[j+1:]
+		} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			switch name {
+			case "type", "output", "output-dir":
+				i++
+				value = strings.Trim(args[i], `"'`)
+			}
+		}
+		if name == "type" && flags[name] != "" {
+			value = flags[name] + ";" + value
+		}
+		flags[name] = value
+	}
+	if _, ok := flags["testpackage"]; ok {
+		return "", false
+	}
+	switch output := flags["output"]; output {
+	case "stdout":
+		return "", false
+	case "":
+	default:
+		return output, true
+	}
+	typ := flags["type"]
+	if typ == "" {
+		var ok bool
+		if typ, ok = defaultType(); !ok {
+			return "", false
+		}
+	}
+	specs, err := gen.ParseTypeSpecs(typ, "", "")
+	if err != nil || len(specs) == 0 {
+		return "", false
+	}
+	return filepath.Join(flags["output-dir"], gen.OutputBase(specs[0].Name)+"_mapconst.go"), true
+}
+
+// isMapconst reports whether the command or package path is mapconst.
+func isMapconst(command string) bool {
+	if i := strings.Index(command, "@"); i >= 0 {
+		command = command[:i]
+	}
+	return command == "mapconst" || strings.HasSuffix(command, "/mapconst")
+}
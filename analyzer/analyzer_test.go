@@ -0,0 +1,12 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/empirefox/mapconst/analyzer"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "deprecated", "lookupmap", "lookupswitch", "lookupperfecthash", "lookupbinarysearch", "lookupblob", "directives", "typesdirective", "aliasesfirst")
+}
@@ -0,0 +1,18 @@
+package aliasesfirst
+
+type Status int
+
+const (
+	Active Status = iota
+	ACTIVE
+	Inactive
+	Pending
+)
+
+type Mode int
+
+const (
+	ReadOnly Mode = iota
+	Readonly
+	ReadWrite
+)
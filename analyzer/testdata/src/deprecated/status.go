@@ -0,0 +1,11 @@
+package deprecated
+
+type Status int
+
+const (
+	Active Status = iota
+	Inactive
+	// Deprecated: use Inactive.
+	Disabled // want Disabled:"deprecated"
+	Pending
+)
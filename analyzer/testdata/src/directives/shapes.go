@@ -0,0 +1,29 @@
+package directives
+
+//go:generate mapconst -type=Color // want "color_mapconst.go does not exist; run go generate"
+type Color int
+
+const (
+	Red Color = iota
+	Green
+)
+
+//go:generate mapconst // want "shape_mapconst.go does not exist; run go generate"
+const (
+	Circle Shape = iota
+	Square
+)
+
+type Shape int
+
+//go:generate go run github.com/empirefox/mapconst
+const (
+	Small Size = iota
+	Large
+)
+
+type Size int
+
+//go:generate mapconst -output=stdout
+//go:generate mapconst
+var untyped = 0
@@ -0,0 +1,9 @@
+package lookupbinarysearch
+
+type Status int
+
+const (
+	Active Status = iota
+	Inactive
+	Pending
+)
@@ -0,0 +1,9 @@
+package lookupblob
+
+type Status int
+
+const (
+	Active Status = iota
+	Inactive
+	Pending
+)
@@ -0,0 +1,9 @@
+package lookupmap
+
+type Status int
+
+const (
+	Active Status = iota
+	Inactive
+	Pending
+)
@@ -0,0 +1,9 @@
+package lookupperfecthash
+
+type Status int
+
+const (
+	Active Status = iota
+	Inactive
+	Pending
+)
@@ -0,0 +1,9 @@
+package lookupswitch
+
+type Status int
+
+const (
+	Active Status = iota
+	Inactive
+	Pending
+)
@@ -0,0 +1,20 @@
+// Package typesdirective lists the types to generate in a directive.
+//
+//mapconst:types=Mode,Level
+package typesdirective
+
+//go:generate mapconst // want "mode_mapconst.go does not exist; run go generate"
+
+type Mode int
+
+const (
+	ReadOnly Mode = iota
+	ReadWrite
+)
+
+type Level int
+
+const (
+	Low Level = iota
+	High
+)
@@ -0,0 +1,14 @@
+// Mapconstvet reports name maps generated by mapconst that are missing or
+// out of date. It is run by go vet:
+//
+//	go vet -vettool=$(which mapconstvet) ./...
+package main
+
+import (
+	"github.com/empirefox/mapconst/analyzer"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/empirefox/mapconst/gen"
+)
+
+// subcommands maps the name of each subcommand to the function running it
+// with the arguments that follow the name.
+var subcommands = map[string]func(args []string){
+	"generate":   generate,
+	"verify":     verify,
+	"list":       list,
+	"clean":      clean,
+	"completion": completion,
+}
+
+// usage prints the synopsis of the subcommands and the flags of generate.
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, `Usage:
+	mapconst [generate] [flags] -type T [directories | files | import path | -]
+	mapconst verify [flags] -type T [directories | files | import path]
+	mapconst list [-type T] [directory | files | import path]
+	mapconst clean [-type T] [directory | ./... ...]
+	mapconst completion bash | zsh | fish
+
+generate, the default, writes the generated code, into each directory if
+there are several. verify writes nothing and fails if a file generate would
+write is missing or out of date. list prints the types with constants and
+their names. clean removes the files mapconst generated, with -type only
+those of the given types; a directory ending in /... includes its
+subdirectories. completion prints the script completing the subcommands,
+flags and -type names in the shell. Run mapconst <subcommand> -h for the
+flags of list and clean.
+
+Flags of generate and verify:
+`)
+	flag.PrintDefaults()
+}
+
+// verify implements the verify subcommand: it generates as generate does,
+// but instead of writing the files, it fails if they would change.
+func verify(args []string) {
+	config.verify = true
+	generate(args)
+}
+
+// list implements the list subcommand, printing each type and the names and
+// values of its constants.
+func list(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	var c gen.Config
+	typeNames := fs.String("type", "", "comma-separated list of type names; default all types with constants")
+	typesOnly := fs.Bool("types", false, "print only the names of the types with constants, one per line")
+	fs.StringVar(&c.TrimPrefix, "trimprefix", "", "prefix to trim from the constant names to form map keys")
+	fs.StringVar(&c.Transform, "transform", "none", "transform of the trimmed constant names to map keys")
+	fs.StringVar(&c.KeyPrefix, "keyprefix", "", "prefix added to the map keys after the transform")
+	fs.StringVar(&c.KeySuffix, "keysuffix", "", "suffix added to the map keys after the transform")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		args = fs.Args()
+	} else {
+		args = []string{"."}
+	}
+	g, err := gen.Load(args, &c)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	if *typesOnly {
+		for _, name := range g.ConstTypes() {
+			fmt.Println(name)
+		}
+		return
+	}
+	names := *typeNames
+	if names == "" {
+		for i, name := range g.ConstTypes() {
+			if i > 0 {
+				names += ","
+			}
+			names += name
+		}
+		if names == "" {
+			return
+		}
+	}
+	specs, err := gen.ParseTypeSpecs(names, c.TrimPrefix, c.Transform)
+	if err != nil {
+		fatalf("invalid -type: %s", err)
+	}
+	for _, spec := range specs {
+		if err := g.Generate(spec); err != nil {
+			errorf("%s", err)
+		}
+	}
+	for _, name := range g.Types() {
+		fmt.Println(name)
+		for _, v := range g.Consts(name) {
+			fmt.Printf("\t%s\t%s\t%s\n", v.Name, v.Lit, v.Key)
+		}
+	}
+}
+
+// clean implements the clean subcommand, removing the files that mapconst
+// generated in the directories, each of which may end in /... to include
+// its subdirectories, as in package patterns. With -type, only the files
+// generated for the listed types alone are removed, e.g. after renaming
+// them.
+func clean(args []string) {
+	fs := flag.NewFlagSet("clean", flag.ExitOnError)
+	dryRun := fs.Bool("n", false, "print the files to remove without removing them")
+	typeNames := fs.String("type", "", "comma-separated list of type names; remove only the files generated for some of these types and no others")
+	fs.Parse(args)
+	var only map[string]bool
+	if *typeNames != "" {
+		specs, err := gen.ParseTypeSpecs(*typeNames, "", "none")
+		if err != nil {
+			fatalf("invalid -type: %s", err)
+		}
+		only = make(map[string]bool)
+		for _, spec := range specs {
+			only[spec.Name] = true
+		}
+	}
+	dirs := fs.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	for _, dir := range dirs {
+		recursive := false
+		if dir == "..." || strings.HasSuffix(dir, "/...") {
+			dir, recursive = strings.TrimSuffix(strings.TrimSuffix(dir, "..."), "/"), true
+			if dir == "" {
+				dir = "."
+			}
+		}
+		err := filepath.Walk(dir, func(filename string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				// Skip what the go command skips in patterns.
+				name := info.Name()
+				if filename != dir && (!recursive || name == "vendor" || name == "testdata" ||
+					strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			data, err := ioutil.ReadFile(filename)
+			if err != nil {
+				return err
+			}
+			if !isGenerated(data) || only != nil && !generatedFor(data, only) {
+				return nil
+			}
+			fmt.Println(filename)
+			if *dryRun {
+				return nil
+			}
+			return os.Remove(filename)
+		})
+		if err != nil {
+			fatalf("%s", err)
+		}
+	}
+}
+
+// generatedFor reports whether the -type flags of the command line in the
+// "Code generated" line of the generated file content name some types and
+// only types in names. A file generated with -append holds such a line per
+// section, and all of them must.
+func generatedFor(data []byte, names map[string]bool) bool {
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.Index(line, `Code generated by "mapconst`)
+		if i < 0 {
+			continue
+		}
+		line = line[i+len(`Code generated by "`):]
+		if j := strings.Index(line, `";`); j >= 0 {
+			line = line[:j]
+		}
+		var typeNames []string
+		fields := strings.Fields(line)
+		for k := 0; k < len(fields); k++ {
+			f := strings.TrimPrefix(fields[k], "-")
+			switch {
+			case strings.HasPrefix(f, "-type="), strings.HasPrefix(f, "type="):
+				typeNames = append(typeNames, f[strings.Index(f, "=")+1:])
+			case (f == "-type" || f == "type") && k+1 < len(fields):
+				k++
+				typeNames = append(typeNames, fields[k])
+			}
+		}
+		specs, err := gen.ParseTypeSpecs(strings.Join(typeNames, ";"), "", "none")
+		if err != nil || len(specs) == 0 {
+			return false
+		}
+		for _, spec := range specs {
+			if !names[spec.Name] {
+				return false
+			}
+		}
+		found = true
+	}
+	return found
+}
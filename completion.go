@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// subcommandNames lists the subcommands to complete. It cannot be derived
+// from subcommands, which refers to completion.
+const subcommandNames = "generate verify list clean completion"
+
+// completion implements the completion subcommand, printing the completion
+// script of the shell. The scripts complete the subcommands, the flags of
+// generate and, after -type, the types with constants of the package in the
+// current directory, as listed by mapconst list -types.
+func completion(args []string) {
+	if len(args) != 1 {
+		fatalf("usage: mapconst completion bash | zsh | fish")
+	}
+	var flags []*flag.Flag
+	flag.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, f)
+	})
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	names := make([]string, len(flags))
+	for i, f := range flags {
+		names[i] = "-" + f.Name
+	}
+	switch args[0] {
+	case "bash":
+		fmt.Printf(bashCompletion, subcommandNames, strings.Join(names, " "))
+	case "zsh":
+		fmt.Printf(zshCompletion, strings.Join(names, " "), subcommandNames)
+	case "fish":
+		fmt.Print(fishCompletion)
+		for _, f := range flags {
+			option := "-f"
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+				option = "-r"
+			}
+			if f.Name == "type" {
+				option = `-x -a "(mapconst list -types 2>/dev/null)"`
+			}
+			fmt.Printf("complete -c mapconst -o %s %s -d %s\n", f.Name, option, fishQuote(summary(f.Usage)))
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "mapconst completion: unknown shell %q; must be bash, zsh or fish\n", args[0])
+		os.Exit(2)
+	}
+}
+
+// summary returns the first clause of the usage of a flag, short enough to
+// describe it in a completion menu.
+func summary(usage string) string {
+	if i := strings.IndexAny(usage, ";("); i >= 0 {
+		usage = usage[:i]
+	}
+	return strings.TrimSpace(usage)
+}
+
+// fishQuote quotes s as a fish string.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+var bashCompletion = `# bash completion for mapconst; load it with
+#	source <(mapconst completion bash)
+_mapconst() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+	case $cur in
+	-type=* | --type=*)
+		COMPREPLY=($(compgen -P "${cur%%%%=*}=" -W "$(mapconst list -types 2>/dev/null)" -- "${cur#*=}"))
+		return
+		;;
+	esac
+	case $prev in
+	-type | --type)
+		COMPREPLY=($(compgen -W "$(mapconst list -types 2>/dev/null)" -- "$cur"))
+		return
+		;;
+	esac
+	if [[ $COMP_CWORD -eq 1 && $cur != -* ]]; then
+		COMPREPLY=($(compgen -W "%s" -- "$cur") $(compgen -d -- "$cur"))
+	elif [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "%s" -- "$cur"))
+	else
+		COMPREPLY=($(compgen -f -- "$cur"))
+	fi
+}
+complete -F _mapconst mapconst
+`
+
+var zshCompletion = `#compdef mapconst
+# zsh completion for mapconst; load it with
+#	source <(mapconst completion zsh)
+_mapconst() {
+	local -a types
+	case $words[CURRENT-1] in
+	-type | --type)
+		types=(${(f)"$(mapconst list -types 2>/dev/null)"})
+		compadd -a types
+		return
+		;;
+	esac
+	if [[ $words[CURRENT] == -* ]]; then
+		compadd -- %s
+	elif (( CURRENT == 2 )); then
+		compadd -- %s
+		_files -/
+	else
+		_files
+	fi
+}
+compdef _mapconst mapconst
+`
+
+var fishCompletion = `# fish completion for mapconst; load it with
+#	mapconst completion fish | source
+complete -c mapconst -n __fish_use_subcommand -f -a '` + subcommandNames + `'
+complete -c mapconst -n '__fish_seen_subcommand_from completion' -f -a 'bash zsh fish'
+`
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes in a hunk.
+const diffContext = 3
+
+// diffLine is a line of a diff: op is ' ' for a line of both texts, '-' for
+// one of the old text only and '+' for one of the new text only.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// unifiedDiff returns the unified diff turning old, the content of the file
+// oldName, into new, or "" if they are equal. A missing file is /dev/null
+// with no content.
+func unifiedDiff(oldName, newName string, old, new []byte) string {
+	if bytes.Equal(old, new) {
+		return ""
+	}
+	edits := diffEdits(splitLines(old), splitLines(new))
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+	// oldLine and newLine number the lines of the texts before each edit.
+	oldLine := make([]int, len(edits)+1)
+	newLine := make([]int, len(edits)+1)
+	for i, e := range edits {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if e.op != '+' {
+			oldLine[i+1]++
+		}
+		if e.op != '-' {
+			newLine[i+1]++
+		}
+	}
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+		// A hunk runs from the context before a change to the context
+		// after the last change that is close enough to join it.
+		start, end := i-diffContext, i
+		if start < 0 {
+			start = 0
+		}
+		for end < len(edits) {
+			if edits[end].op != ' ' {
+				end++
+				continue
+			}
+			j := end
+			for j < len(edits) && edits[j].op == ' ' {
+				j++
+			}
+			if j == len(edits) || j-end > 2*diffContext {
+				if end += diffContext; end > j {
+					end = j
+				}
+				break
+			}
+			end = j
+		}
+		oldStart, oldCount := oldLine[start], oldLine[end]-oldLine[start]
+		newStart, newCount := newLine[start], newLine[end]-newLine[start]
+		if oldCount > 0 {
+			oldStart++
+		}
+		if newCount > 0 {
+			newStart++
+		}
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, e := range edits[start:end] {
+			buf.WriteByte(e.op)
+			buf.WriteString(e.text)
+			buf.WriteByte('\n')
+		}
+		i = end
+	}
+	return buf.String()
+}
+
+// splitLines returns the lines of text without their line endings.
+func splitLines(text []byte) []string {
+	if len(text) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(text), "\n"), "\n")
+}
+
+// diffEdits returns a shortest list of edits turning the lines a into b.
+// Generated files mostly change in few places, so after trimming the
+// common prefix and suffix, what remains is compared by a longest common
+// subsequence, unless it is too large, in which case it is replaced as a
+// whole.
+func diffEdits(a, b []string) []diffLine {
+	var prefix, suffix int
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	var edits []diffLine
+	for _, line := range a[:prefix] {
+		edits = append(edits, diffLine{' ', line})
+	}
+	x, y := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(x)*len(y) > 1<<22 {
+		for _, line := range x {
+			edits = append(edits, diffLine{'-', line})
+		}
+		for _, line := range y {
+			edits = append(edits, diffLine{'+', line})
+		}
+	} else {
+		// lcs[i][j] is the length of the longest common subsequence of
+		// x[i:] and y[j:].
+		lcs := make([][]int, len(x)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(y)+1)
+		}
+		for i := len(x) - 1; i >= 0; i-- {
+			for j := len(y) - 1; j >= 0; j-- {
+				switch {
+				case x[i] == y[j]:
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				case lcs[i+1][j] >= lcs[i][j+1]:
+					lcs[i][j] = lcs[i+1][j]
+				default:
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(x) || j < len(y) {
+			switch {
+			case i < len(x) && j < len(y) && x[i] == y[j]:
+				edits = append(edits, diffLine{' ', x[i]})
+				i++
+				j++
+			case j == len(y) || i < len(x) && lcs[i+1][j] >= lcs[i][j+1]:
+				edits = append(edits, diffLine{'-', x[i]})
+				i++
+			default:
+				edits = append(edits, diffLine{'+', y[j]})
+				j++
+			}
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, diffLine{' ', line})
+	}
+	return edits
+}
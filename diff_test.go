@@ -0,0 +1,170 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// numbers returns the lines of the numbers from lo to hi, those of the keys
+// of replace replaced by its values, e.g. 5 by X for {5: "X"}.
+func numbers(lo, hi int, replace map[int]string) []string {
+	var lines []string
+	for n := lo; n <= hi; n++ {
+		if line, ok := replace[n]; ok {
+			lines = append(lines, line)
+			continue
+		}
+		lines = append(lines, strconv.Itoa(n))
+	}
+	return lines
+}
+
+// text returns the lines of the groups as file content.
+func text(groups ...[]string) []byte {
+	var lines []string
+	for _, g := range groups {
+		lines = append(lines, g...)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	ten := text(numbers(1, 10, nil))
+	twenty := text(numbers(1, 20, nil))
+	for _, tt := range []struct {
+		name     string
+		old, new []byte
+		want     string
+	}{
+		{"equal", ten, ten, ""},
+		{"insert", ten, text(numbers(1, 5, nil), []string{"x"}, numbers(6, 10, nil)), `@@ -3,6 +3,7 @@
+ 3
+ 4
+ 5
++x
+ 6
+ 7
+ 8
+`},
+		{"delete", ten, text(numbers(2, 10, nil)), `@@ -1,4 +1,3 @@
+-1
+ 2
+ 3
+ 4
+`},
+		{"replace", ten, text(numbers(1, 10, map[int]string{5: "X"})), `@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++X
+ 6
+ 7
+ 8
+`},
+		{"both ends", twenty, text([]string{"0"}, numbers(1, 20, map[int]string{20: "Y"})), `@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -17,4 +18,4 @@
+ 17
+ 18
+ 19
+-20
++Y
+`},
+		{"joined context", twenty, text(numbers(1, 20, map[int]string{5: "A", 11: "B"})), `@@ -2,13 +2,13 @@
+ 2
+ 3
+ 4
+-5
++A
+ 6
+ 7
+ 8
+ 9
+ 10
+-11
++B
+ 12
+ 13
+ 14
+`},
+		{"separate hunks", twenty, text(numbers(1, 20, map[int]string{5: "A", 13: "B"})), `@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++A
+ 6
+ 7
+ 8
+@@ -10,7 +10,7 @@
+ 10
+ 11
+ 12
+-13
++B
+ 14
+ 15
+ 16
+`},
+		{"new file", nil, text([]string{"a", "b"}), `@@ -0,0 +1,2 @@
++a
++b
+`},
+		{"emptied", text([]string{"a", "b"}), nil, `@@ -1,2 +0,0 @@
+-a
+-b
+`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unifiedDiff("old", "new", tt.old, tt.new)
+			want := tt.want
+			if want != "" {
+				want = "--- old\n+++ new\n" + want
+			}
+			if got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+// TestUnifiedDiffLarge checks that texts too different to compare line by
+// line are replaced as a whole, between their common prefix and suffix.
+func TestUnifiedDiffLarge(t *testing.T) {
+	const n = 2100 // n*n lines to compare exceed the limit of 1<<22.
+	var x, y []string
+	for i := 0; i < n; i++ {
+		x = append(x, "x"+strconv.Itoa(i))
+		y = append(y, "y"+strconv.Itoa(i))
+	}
+	// A line common to both is replaced along with the rest, as it would
+	// not be by a comparison line by line.
+	x[n/2], y[n/2] = "shared", "shared"
+	old := text([]string{"head"}, x, []string{"tail"})
+	new := text([]string{"head"}, y, []string{"tail"})
+	lines := strings.Split(strings.TrimSuffix(unifiedDiff("old", "new", old, new), "\n"), "\n")
+	want := []string{"--- old", "+++ new", "@@ -1,2102 +1,2102 @@", " head"}
+	for _, line := range x {
+		want = append(want, "-"+line)
+	}
+	for _, line := range y {
+		want = append(want, "+"+line)
+	}
+	want = append(want, " tail")
+	if len(lines) != len(want) {
+		t.Fatalf("%d lines, want %d", len(lines), len(want))
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("line %d is %q, want %q", i+1, lines[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package gen
+
+import (
+	"bufio"
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// The marker comments delimiting the code of each type when Append is set,
+// followed by the type name.
+const (
+	sectionBegin = "// mapconst:begin "
+	sectionEnd   = "// mapconst:end "
+)
+
+// Merge merges the output with existing, the content of the Go file that
+// the output replaces, generated with Append set: the code of the types
+// generated again replaces theirs in place, that of the others is kept,
+// along with the imports it needs, and that of new types follows. Code of
+// existing outside the markers, such as that of a file generated without
+// Append, is dropped. It must be called after Generate.
+func (g *Generator) Merge(existing []byte) error {
+	if !g.cfg.Append {
+		return nil
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "", existing, parser.ImportsOnly)
+	if err != nil {
+		return err
+	}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return err
+		}
+		name := importName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if _, ok := g.imports[name]; !ok {
+			g.addImport(name, importPath)
+		}
+	}
+
+	oldNames, oldSections := sections(existing)
+	newNames, newSections := sections(g.buf.Bytes())
+	var buf, merged bytes.Buffer
+	for _, name := range oldNames {
+		if section, ok := newSections[name]; ok {
+			buf.WriteString(section)
+			continue
+		}
+		buf.WriteString(oldSections[name])
+		merged.WriteString(oldSections[name])
+	}
+	for _, name := range newNames {
+		if _, ok := oldSections[name]; !ok {
+			buf.WriteString(newSections[name])
+		}
+	}
+	g.buf = buf
+	g.merged = merged.String()
+	return nil
+}
+
+// sections returns the names of the types of the Go source in order, and
+// their code including the markers delimiting it.
+func sections(src []byte) (names []string, code map[string]string) {
+	code = make(map[string]string)
+	var name string
+	var section strings.Builder
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	scanner.Buffer(nil, len(src)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, sectionBegin):
+			name = strings.TrimPrefix(line, sectionBegin)
+			section.Reset()
+			section.WriteString("\n")
+		case name == "":
+			continue
+		}
+		section.WriteString(line + "\n")
+		if line == sectionEnd+name {
+			if _, ok := code[name]; !ok {
+				names = append(names, name)
+			}
+			code[name] = section.String()
+			name = ""
+		}
+	}
+	return names, code
+}
@@ -0,0 +1,71 @@
+package gen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSections(t *testing.T) {
+	src := `package p
+
+var outside = 0
+
+// mapconst:begin Status
+var a = 1
+// mapconst:end Status
+
+// mapconst:begin Level
+var b = 2
+// mapconst:begin Region
+var c = 3
+// mapconst:end Region
+// mapconst:end Level
+
+// mapconst:begin Mode
+var unterminated = 4
+`
+	names, code := sections([]byte(src))
+	if want := []string{"Status", "Region"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %q, want %q", names, want)
+	}
+	want := map[string]string{
+		"Status": "\n// mapconst:begin Status\nvar a = 1\n// mapconst:end Status\n",
+		"Region": "\n// mapconst:begin Region\nvar c = 3\n// mapconst:end Region\n",
+	}
+	if !reflect.DeepEqual(code, want) {
+		t.Errorf("code = %q, want %q", code, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	existing := source(t, load(t, statusSrc, &Config{Append: true, Transform: "lower"}, "Status", "Level"))
+
+	g := load(t, statusSrc, &Config{Append: true}, "Status", "Region")
+	if err := g.Merge([]byte(existing)); err != nil {
+		t.Fatal(err)
+	}
+	out := source(t, g)
+	names, code := sections([]byte(out))
+	if want := []string{"Status", "Level", "Region"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("sections = %q, want %q", names, want)
+	}
+	wantContains(t, code["Status"], `"Active":`)
+	wantContains(t, code["Level"], `"debug":`)
+	wantContains(t, code["Region"], `"USEast":`)
+	if strings.Contains(code["Status"], `"active"`) {
+		t.Errorf("the section of Status was not replaced:\n%s", code["Status"])
+	}
+	typeCheck(t, statusSrc, out)
+}
+
+func TestMergeWithoutAppend(t *testing.T) {
+	g := load(t, statusSrc, nil, "Status")
+	before := source(t, g)
+	if err := g.Merge([]byte("not even Go")); err != nil {
+		t.Fatal(err)
+	}
+	if after := source(t, g); after != before {
+		t.Errorf("Merge without Append changed the output:\n%s", after)
+	}
+}
@@ -0,0 +1,44 @@
+package gen
+
+import (
+	"encoding/json"
+	"go/build"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// cgoFiles returns the Go files of the package in directory as the compiler
+// sees them, after cgo has translated references to C. Type checking those
+// resolves constants whose values come from C, e.g. C.FOO_BAR, which are
+// unknown in the original files. It runs go list and so needs a C compiler.
+// The //line comments cgo leaves make positions refer to the original files.
+func cgoFiles(ctxt *build.Context, directory string) []string {
+	cmd := exec.Command("go", "list", "-compiled", "-json=Dir,CompiledGoFiles", ".")
+	cmd.Dir = directory
+	cmd.Env = append(os.Environ(),
+		"CGO_ENABLED=1",
+		"GOOS="+ctxt.GOOS,
+		"GOARCH="+ctxt.GOARCH,
+	)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		fatalf("running cgo on %s: %s", directory, err)
+	}
+	var pkg struct {
+		Dir             string
+		CompiledGoFiles []string
+	}
+	if err := json.Unmarshal(out, &pkg); err != nil {
+		fatalf("running cgo on %s: %s", directory, err)
+	}
+	names := make([]string, len(pkg.CompiledGoFiles))
+	for i, name := range pkg.CompiledGoFiles {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(pkg.Dir, name)
+		}
+		names[i] = name
+	}
+	return names
+}
@@ -0,0 +1,29 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/csv"
+)
+
+// CSV returns a row per constant of the generated types, after a header
+// row: the type, the constant name, its value and its doc comment. The
+// fields are separated by comma, or by tab. The rows follow comment lines
+// starting with #, holding the "Code generated" line, which readers skip by
+// setting the Comment field of csv.Reader.
+func (g *Generator) CSV(comma rune) (src []byte, err error) {
+	defer catch(&err)
+	var buf bytes.Buffer
+	buf.WriteString(g.cfg.licenseHeader("#"))
+	buf.WriteString(g.cfg.generatedBy("#"))
+	w := csv.NewWriter(&buf)
+	w.Comma = comma
+	w.Write([]string{"type", "name", "value", "comment"})
+	for _, data := range g.types {
+		for _, c := range data.Consts {
+			value, _ := literal(c.Value)
+			w.Write([]string{data.Type, c.Name, value, c.Doc})
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
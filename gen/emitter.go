@@ -0,0 +1,167 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Emitter writes the generated types in a format of its own, such as
+// another language or a schema, alongside the Go code. It reads the types
+// from the Generator once they are generated, through Enums or the methods
+// of the built-in formats.
+type Emitter interface {
+	// Emit returns the files to write. filename is the one the user named
+	// for the output; most emitters write just that file.
+	Emit(g *Generator, filename string) ([]Output, error)
+}
+
+// Output is a file written by an Emitter.
+type Output struct {
+	Name    string
+	Content []byte
+}
+
+// EmitterFunc adapts a function to the Emitter interface.
+type EmitterFunc func(g *Generator, filename string) ([]Output, error)
+
+// Emit calls f(g, filename).
+func (f EmitterFunc) Emit(g *Generator, filename string) ([]Output, error) {
+	return f(g, filename)
+}
+
+// emitters maps the names of the registered emitters to them.
+var emitters = make(map[string]Emitter)
+
+// RegisterEmitter makes the emitter available by name, e.g. to the -emit
+// flag of a mapconst command built with the package registering it. It
+// panics if the name is registered twice.
+func RegisterEmitter(name string, e Emitter) {
+	if _, dup := emitters[name]; dup {
+		panic("mapconst: emitter " + name + " registered twice")
+	}
+	emitters[name] = e
+}
+
+// LookupEmitter returns the emitter registered by name, if any.
+func LookupEmitter(name string) (Emitter, bool) {
+	e, ok := emitters[name]
+	return e, ok
+}
+
+// EmitterNames returns the names of the registered emitters, sorted.
+func EmitterNames() []string {
+	names := make([]string, 0, len(emitters))
+	for name := range emitters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// single returns the emitter writing the output of emit to the file the
+// user named.
+func single(emit func(g *Generator) ([]byte, error)) Emitter {
+	return EmitterFunc(func(g *Generator, filename string) ([]Output, error) {
+		src, err := emit(g)
+		if err != nil {
+			return nil, err
+		}
+		return []Output{{Name: filename, Content: src}}, nil
+	})
+}
+
+func init() {
+	RegisterEmitter("ts", single((*Generator).TypeScript))
+	RegisterEmitter("openapi", single((*Generator).OpenAPI))
+	RegisterEmitter("graphql", single((*Generator).GraphQL))
+	RegisterEmitter("md", single((*Generator).Markdown))
+	RegisterEmitter("kubebuilder", single((*Generator).Kubebuilder))
+	RegisterEmitter("swag", single((*Generator).Swag))
+	RegisterEmitter("proto", single((*Generator).Proto))
+	RegisterEmitter("csv", EmitterFunc(func(g *Generator, filename string) ([]Output, error) {
+		comma := ','
+		if strings.HasSuffix(filename, ".tsv") {
+			comma = '\t'
+		}
+		src, err := g.CSV(comma)
+		if err != nil {
+			return nil, err
+		}
+		return []Output{{Name: filename, Content: src}}, nil
+	}))
+}
+
+// Enum is a generated type as emitters see it.
+type Enum struct {
+	Type       string      `json:"type"`                 // The name of the type, qualified if declared in another package.
+	Underlying string      `json:"underlying,omitempty"` // The underlying basic type, e.g. "int".
+	Consts     []EnumConst `json:"constants"`            // The constants, in declaration order.
+}
+
+// EnumConst is a constant of an Enum.
+type EnumConst struct {
+	Name    string   `json:"name"`
+	Key     string   `json:"key"`               // The key of the constant in the name map.
+	Aliases []string `json:"aliases,omitempty"` // Further keys, set by a //mapconst:aliases= directive.
+	Value   string   `json:"value"`             // The value as a literal, e.g. 200 or us-east-1; empty if unresolved.
+	Doc     string   `json:"doc,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+	Default bool     `json:"default,omitempty"`
+}
+
+// Enums returns the types generated so far, in order.
+func (g *Generator) Enums() []Enum {
+	enums := make([]Enum, len(g.types))
+	for i, data := range g.types {
+		e := Enum{Type: data.TypeQual + data.Type, Underlying: data.Underlying, Consts: make([]EnumConst, len(data.Consts))}
+		for j, c := range data.Consts {
+			lit, _ := literal(c.Value)
+			e.Consts[j] = EnumConst{Name: c.Name, Key: c.Key, Aliases: c.Aliases, Value: lit, Doc: c.Doc, Groups: c.Groups, Default: c.Default}
+		}
+		enums[i] = e
+	}
+	return enums
+}
+
+// ExecEmitter is an Emitter run as a separate program, which need not be
+// written in Go: the program gets the file name as its argument and, on
+// its standard input, a JSON object with the package name in "package", the
+// Enums in "enums" and in "generated" the text of the "Code generated" line
+// marking the file as generated by mapconst. Its standard output is the
+// content of the file, which must start with comments holding that line,
+// each starting with //, # or --, or within <!-- and -->: mapconst replaces
+// and removes only the files it recognizes as generated.
+type ExecEmitter struct {
+	Path string // The program, looked up in PATH unless it holds a slash.
+}
+
+// Emit runs the program.
+func (e ExecEmitter) Emit(g *Generator, filename string) ([]Output, error) {
+	generated := strings.TrimSpace(g.cfg.generatedLine(""))
+	input, err := json.Marshal(struct {
+		Package   string `json:"package"`
+		Enums     []Enum `json:"enums"`
+		Generated string `json:"generated"`
+	}{g.pkg.name, g.Enums(), generated})
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(e.Path, filename)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s: %s", e.Path, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %s", e.Path, err)
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte(generated)) {
+		return nil, fmt.Errorf("%s: output lacks the line %q", e.Path, generated)
+	}
+	return []Output{{Name: filename, Content: stdout.Bytes()}}, nil
+}
@@ -0,0 +1,82 @@
+package gen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEmitterNames(t *testing.T) {
+	want := []string{"csv", "graphql", "kubebuilder", "md", "openapi", "proto", "swag", "ts"}
+	if got := EmitterNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EmitterNames() = %q, want %q", got, want)
+	}
+	if _, ok := LookupEmitter("yaml"); ok {
+		t.Error("LookupEmitter found an emitter never registered")
+	}
+}
+
+func TestRegisterEmitterTwice(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "mapconst: emitter ts registered twice" {
+			t.Errorf("recovered %v", r)
+		}
+	}()
+	RegisterEmitter("ts", single((*Generator).TypeScript))
+}
+
+// TestEmitters checks that each built-in emitter writes the file named,
+// marked as generated, with the constants of the type.
+func TestEmitters(t *testing.T) {
+	for _, tt := range []struct {
+		emitter, filename string
+		want              []string
+	}{
+		{"csv", "status.csv", []string{"# Code generated by", "type,name,value,comment\n", "Status,Active,0,Active is in use.\n"}},
+		{"csv", "status.tsv", []string{"# Code generated by", "type\tname\tvalue\tcomment\n", "Status\tDisabled\t2\tDeprecated: use Inactive.\n"}},
+		{"graphql", "status.graphql", []string{"# Code generated by", "enum Status {\n  Active\n  Pending\n"}},
+		{"kubebuilder", "status.txt", []string{"// Code generated by", "// +kubebuilder:validation:Enum=Active;Pending;Disabled;Inactive\n"}},
+		{"md", "status.md", []string{"<!--\n Code generated by", "| `Active` | `0` | `Active` | Active is in use. |\n"}},
+		{"openapi", "status.yaml", []string{"# Code generated by", "    Status:\n      type: string\n", `        - "Inactive"`}},
+		{"proto", "status.proto", []string{"// Code generated by", "package p;\n", "  STATUS_ACTIVE = 0;\n"}},
+		{"swag", "status.txt", []string{"// Code generated by", "Enums(Active, Pending, Disabled, Inactive)"}},
+		{"ts", "status.ts", []string{"// Code generated by", "  Active: 0,\n", "export type Status ="}},
+	} {
+		e, ok := LookupEmitter(tt.emitter)
+		if !ok {
+			t.Errorf("no emitter %s", tt.emitter)
+			continue
+		}
+		out, err := e.Emit(load(t, statusSrc, nil, "Status"), tt.filename)
+		if err != nil {
+			t.Errorf("%s: %v", tt.emitter, err)
+			continue
+		}
+		if len(out) != 1 || out[0].Name != tt.filename {
+			t.Errorf("%s: wrote %d files, want just %s", tt.emitter, len(out), tt.filename)
+			continue
+		}
+		wantContains(t, string(out[0].Content), tt.want...)
+	}
+}
+
+func TestEnums(t *testing.T) {
+	enums := load(t, statusSrc, &Config{Transform: "snake"}, "Status", "Region").Enums()
+	if len(enums) != 2 {
+		t.Fatalf("%d enums, want 2", len(enums))
+	}
+	status, region := enums[0], enums[1]
+	if status.Type != "Status" || status.Underlying != "int" || len(status.Consts) != 4 {
+		t.Errorf("Status enum = %+v", status)
+	}
+	want := EnumConst{Name: "Pending", Key: "pending", Aliases: []string{"running"}, Value: "1", Groups: []string{"live"}}
+	if got := status.Consts[1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pending = %+v, want %+v", got, want)
+	}
+	if got := status.Consts[3]; !got.Default {
+		t.Errorf("Inactive = %+v, want the default", got)
+	}
+	if got := region.Consts[0]; got.Key != "us_east" || got.Value != "us-east-1" || !strings.EqualFold(region.Underlying, "string") {
+		t.Errorf("USEast = %+v of %s", got, region.Underlying)
+	}
+}
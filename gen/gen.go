@@ -0,0 +1,2298 @@
+//go:build go1.5
+// +build go1.5
+
+// Package gen generates maps of the names of constants to their values, and
+// code built upon them, for the types of a Go package. It is what the
+// mapconst command runs; code generators can embed it instead of running
+// the command.
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+var headerTmpl string = `%[1]s%[3]s
+package %[2]s
+%[4]s`
+
+type mapConstData struct {
+	Type       string
+	Qual       string  // Qualifier of the constants, e.g. "status.", when generating into another package.
+	TypeQual   string  // Qualifier of the type; differs from Qual for types declared in another package.
+	Consts     []Value // All constants, in declaration order.
+	Entries    []Value // The entries of the name lookup: the constants, then one per alias keyed by it.
+	Unique     []Value // Constants with distinct values; the first declared wins.
+	Underlying string  // The underlying basic type, e.g. "int".
+	Unsigned   bool    // Whether the underlying type is an unsigned integer.
+	Lookup     string  // How names are looked up: map, switch, perfecthash or binarysearch.
+	NoAlloc    bool    // Whether to avoid package-level maps.
+	Lazy       bool    // Whether maps are built on first use.
+	Hash       *perfectHash
+	Var        string // Identifier of the name lookup, a map or a function.
+	Names      string // Identifier of the map of values to names, if any.
+	Values     string // Identifier of the map of value literals to constants, if any.
+	Count      string // Identifier of the number of distinct values, if any.
+	Default    string // The constant decoded from unknown names, if Lenient is set.
+	DocConsts  bool   // Whether doc comments list the constants.
+	Blob       bool   // Whether the maps are filled from a blob of the names, for large sets of constants.
+
+	skipped []string // Methods not generated as they are written by hand.
+}
+
+// BlobString returns the keys of the constants concatenated.
+func (d *mapConstData) BlobString() string {
+	var b strings.Builder
+	for _, c := range d.Entries {
+		b.WriteString(c.Key)
+	}
+	return b.String()
+}
+
+// BlobOffsets returns the offsets of the keys of the constants in
+// BlobString, followed by its length.
+func (d *mapConstData) BlobOffsets() []int {
+	offsets := make([]int, 0, len(d.Entries)+1)
+	n := 0
+	for _, c := range d.Entries {
+		offsets = append(offsets, n)
+		n += len(c.Key)
+	}
+	return append(offsets, n)
+}
+
+// Value is a constant of the type being generated. The input hash covers
+// its exported fields; see inputHash.
+type Value struct {
+	Name    string         // The name of the constant.
+	Key     string         // The key of the constant in the name map: its name, trimmed and transformed.
+	Value   constant.Value // The resolved value; nil if it could not be type-checked.
+	Lit     string         // The value as written in wire formats, the key of the value map.
+	Doc     string         // The doc comment of the constant, or else its line comment.
+	Groups  []string       // The groups of the constant, set by //mapconst:group= directives.
+	Default bool           // Whether the constant is marked by a //mapconst:default directive.
+	Proto   string         // The value name in the ProtoEnum, set by a //mapconst:proto= directive.
+	Aliases []string       // Further keys of the constant in the name map, set by a //mapconst:aliases= directive.
+	pos     token.Pos      // The position of the name of the constant.
+}
+
+// constListTpl documents which constants a declaration covers, if DocConsts is set.
+var constListTpl string = `
+{{- define "constList"}}
+{{- if .DocConsts}}
+//
+// The {{.Type}} constants are:
+//
+{{- range .Consts}}
+//	{{.Name}}
+{{- end}}
+{{- end}}
+{{- end}}`
+
+// blobThreshold is the number of constants above which the maps are filled
+// from a blob instead of composite literals, which the compiler handles
+// slowly and compiles into large code when they have thousands of entries.
+const blobThreshold = 1000
+
+// blobTpl declares the names of the constants concatenated into one string,
+// with the offsets delimiting them and the constants in the same order, if
+// Blob is set.
+var blobTpl string = `
+// _{{.Type}}_blob holds the names of the {{.Type}} constants, concatenated.
+const _{{.Type}}_blob = {{printf "%q" .BlobString}}
+
+// _{{.Type}}_blobOffsets holds the offsets of the names in _{{.Type}}_blob;
+// the ith name runs up to the offset i+1.
+var _{{.Type}}_blobOffsets = [...]uint32{
+	{{range .BlobOffsets}} {{.}},
+	{{end}}
+}
+
+// _{{.Type}}_blobValues holds the {{.Type}} constants in the order of their
+// names in _{{.Type}}_blob.
+var _{{.Type}}_blobValues = [...]{{.TypeQual}}{{.Type}}{
+	{{range .Entries}} {{$.Qual}}{{.Name}},
+	{{end}}
+}
+`
+
+var mapConstTpl string = `
+{{- if .Blob}}
+{{- if .Lazy}}
+// {{.Var}} returns the map of the names of the {{.Type}} constants to their
+// values, which is built on first use.
+{{- template "constList" .}}
+var {{.Var}} = sync.OnceValue(func() map[string]{{.TypeQual}}{{.Type}} {
+	m := make(map[string]{{.TypeQual}}{{.Type}}, len(_{{.Type}}_blobValues))
+	for i, v := range _{{.Type}}_blobValues {
+		m[_{{.Type}}_blob[_{{.Type}}_blobOffsets[i]:_{{.Type}}_blobOffsets[i+1]]] = v
+	}
+	return m
+})
+{{- else}}
+// {{.Var}} maps the names of the {{.Type}} constants to their values. It is
+// filled at init, so package-level variables must not use it.
+{{- template "constList" .}}
+var {{.Var}} = make(map[string]{{.TypeQual}}{{.Type}}, len(_{{.Type}}_blobValues))
+
+func init() {
+	for i, v := range _{{.Type}}_blobValues {
+		{{.Var}}[_{{.Type}}_blob[_{{.Type}}_blobOffsets[i]:_{{.Type}}_blobOffsets[i+1]]] = v
+	}
+}
+{{- end}}
+{{- else if .Lazy}}
+// {{.Var}} returns the map of the names of the {{.Type}} constants to their
+// values, which is built on first use.
+{{- template "constList" .}}
+var {{.Var}} = sync.OnceValue(func() map[string]{{.TypeQual}}{{.Type}} {
+	return map[string]{{.TypeQual}}{{.Type}} {
+		{{range .Entries}} {{printf "%q" .Key}}:{{$.Qual}}{{.Name}},
+		{{end}}
+	}
+})
+{{- else}}
+// {{.Var}} maps the names of the {{.Type}} constants to their values.
+{{- template "constList" .}}
+var {{.Var}} = map[string]{{.TypeQual}}{{.Type}} {
+	{{range .Entries}} {{printf "%q" .Key}}:{{$.Qual}}{{.Name}},
+	{{end}}
+}
+{{- end}}
+`
+
+// namesTpl declares the map of the values to their names, if NameMap is set.
+var namesTpl string = `
+{{- if .Blob}}
+{{- if .Lazy}}
+// {{.Names}} returns the map of the {{.Type}} values to the names of their
+// constants, the first declared if several share a value. It is built on
+// first use.
+var {{.Names}} = sync.OnceValue(func() map[{{.TypeQual}}{{.Type}}]string {
+	m := make(map[{{.TypeQual}}{{.Type}}]string, {{len .Unique}})
+	for i, v := range _{{.Type}}_blobValues {
+		if _, ok := m[v]; !ok {
+			m[v] = _{{.Type}}_blob[_{{.Type}}_blobOffsets[i]:_{{.Type}}_blobOffsets[i+1]]
+		}
+	}
+	return m
+})
+{{- else}}
+// {{.Names}} maps the {{.Type}} values to the names of their constants, the
+// first declared if several share a value. It is filled at init, so
+// package-level variables must not use it.
+var {{.Names}} = make(map[{{.TypeQual}}{{.Type}}]string, {{len .Unique}})
+
+func init() {
+	for i, v := range _{{.Type}}_blobValues {
+		if _, ok := {{.Names}}[v]; !ok {
+			{{.Names}}[v] = _{{.Type}}_blob[_{{.Type}}_blobOffsets[i]:_{{.Type}}_blobOffsets[i+1]]
+		}
+	}
+}
+{{- end}}
+{{- else if .Lazy}}
+// {{.Names}} returns the map of the {{.Type}} values to the names of their
+// constants, the first declared if several share a value. It is built on
+// first use.
+var {{.Names}} = sync.OnceValue(func() map[{{.TypeQual}}{{.Type}}]string {
+	return map[{{.TypeQual}}{{.Type}}]string {
+		{{range .Unique}} {{$.Qual}}{{.Name}}:{{printf "%q" .Key}},
+		{{end}}
+	}
+})
+{{- else}}
+// {{.Names}} maps the {{.Type}} values to the names of their constants, the
+// first declared if several share a value.
+var {{.Names}} = map[{{.TypeQual}}{{.Type}}]string {
+	{{range .Unique}} {{$.Qual}}{{.Name}}:{{printf "%q" .Key}},
+	{{end}}
+}
+{{- end}}
+`
+
+// valuesTpl declares the map of the value literals to the constants, if
+// ValueMap is set.
+var valuesTpl string = `
+{{- if .Lazy}}
+// {{.Values}} returns the map of the values of the {{.Type}} constants, as
+// literals, to the constants. It is built on first use.
+var {{.Values}} = sync.OnceValue(func() map[string]{{.TypeQual}}{{.Type}} {
+	return map[string]{{.TypeQual}}{{.Type}} {
+		{{range .Unique}} {{printf "%q" .Lit}}:{{$.Qual}}{{.Name}},
+		{{end}}
+	}
+})
+{{- else}}
+// {{.Values}} maps the values of the {{.Type}} constants, as literals, to the
+// constants.
+var {{.Values}} = map[string]{{.TypeQual}}{{.Type}} {
+	{{range .Unique}} {{printf "%q" .Lit}}:{{$.Qual}}{{.Name}},
+	{{end}}
+}
+{{- end}}
+`
+
+// i18nTpl declares the map of the values to their translation keys, if I18n
+// is set. The keys of .Entries are the translation keys.
+var i18nTpl string = `
+{{- if .Lazy}}
+// {{.I18nKeys}} returns the map of the {{.Type}} values to their translation
+// keys. It is built on first use.
+var {{.I18nKeys}} = sync.OnceValue(func() map[{{.TypeQual}}{{.Type}}]string {
+	return map[{{.TypeQual}}{{.Type}}]string {
+		{{range .Entries}} {{$.Qual}}{{.Name}}:{{printf "%q" .Key}},
+		{{end}}
+	}
+})
+{{- else}}
+// {{.I18nKeys}} maps the {{.Type}} values to their translation keys.
+var {{.I18nKeys}} = map[{{.TypeQual}}{{.Type}}]string {
+	{{range .Entries}} {{$.Qual}}{{.Name}}:{{printf "%q" .Key}},
+	{{end}}
+}
+{{- end}}
+`
+
+// deprecatedTpl declares the map of the deprecated values to the
+// deprecation messages, if Deprecated is set. The keys of .Entries are the
+// messages.
+var deprecatedTpl string = `
+// {{.Deprecated}} maps the values of the deprecated {{.Type}} constants to
+// their deprecation messages.
+var {{.Deprecated}} = map[{{.TypeQual}}{{.Type}}]string {
+	{{range .Entries}} {{$.Qual}}{{.Name}}:{{printf "%q" .Key}},
+	{{end}}
+}
+`
+
+// group is a group of constants set by //mapconst:group= directives.
+type group struct {
+	Name   string  // The name of the group.
+	Func   string  // Identifier of the membership function.
+	Consts []Value // The constants of the group, in declaration order.
+	Unique []Value // The constants of the group with distinct values.
+}
+
+// groupsTpl declares the map of the groups to their constants and a
+// membership function per group, if Groups is set.
+var groupsTpl string = `
+// {{.Var}} maps the groups of the {{.Type}} constants, set by
+// //mapconst:group= directives, to their constants.
+var {{.Var}} = map[string][]{{.TypeQual}}{{.Type}} {
+	{{- range .Groups}}
+	{{printf "%q" .Name}}: { {{- range .Consts}}{{$.Qual}}{{.Name}}, {{end}} },
+	{{- end}}
+}
+{{range .Groups}}
+// {{.Func}} reports whether v is a {{$.Type}} constant of the {{.Name}} group.
+func {{.Func}}(v {{$.TypeQual}}{{$.Type}}) bool {
+	switch v {
+	case {{range $i, $c := .Unique}}{{if $i}}, {{end}}{{$.Qual}}{{$c.Name}}{{end}}:
+		return true
+	}
+	return false
+}
+{{end}}`
+
+// registerTpl registers the type with the registry package, if Register is
+// set.
+var registerTpl string = `
+func init() {
+	registry.Register(&registry.Enum{
+		Type: {{printf "%q" .Path}},
+		Names: []string{ {{- range $i, $c := .Consts}}{{if $i}}, {{end}}{{printf "%q" $c.Key}}{{end}}},
+		Values: []interface{}{ {{- range $i, $c := .Consts}}{{if $i}}, {{end}}{{$.Qual}}{{$c.Name}}{{end}}},
+	})
+}
+`
+
+// likeTpl declares the constraint of the types with the underlying type of
+// the type, if Constraint is set.
+var likeTpl string = `
+// {{.Like}} is the constraint of the types with the underlying type of
+// {{.Type}}, for generic functions over it and types converted from it.
+type {{.Like}} interface {
+	~{{.Underlying}}
+}
+`
+
+// constraintTpl declares the union of all the generated types, if
+// Constraint is set.
+var constraintTpl string = `
+// {{.Enum}} is the constraint satisfied by the enum types generated by
+// mapconst, for generic functions over them.
+type {{.Enum}} interface {
+	{{range $i, $t := .Types}}{{if $i}} | {{end}}{{$t.TypeQual}}{{$t.Type}}{{end}}
+}
+`
+
+// registryTpl declares the registry of all the generated types, if Registry
+// is set.
+var registryTpl string = `
+// {{.Var}} maps the names of the enum types generated by mapconst to maps
+// of the names of their constants to the constants, e.g. for endpoints
+// listing every enum of the package.
+var {{.Var}} = map[string]map[string]interface{}{
+	{{- range $t := .Types}}
+	{{printf "%q" $t.Type}}: {
+		{{- range $t.Consts}}
+		{{printf "%q" .Key}}: {{$t.Qual}}{{.Name}},
+		{{- end}}
+	},
+	{{- end}}
+}
+
+// {{.Lookup}} returns the constant named name of the enum type named
+// typeName, and whether there is one.
+func {{.Lookup}}(typeName, name string) (interface{}, bool) {
+	v, ok := {{.Var}}[typeName][name]
+	return v, ok
+}
+`
+
+// pairsTpl declares the slice of the names and constants in declaration
+// order, if Pairs is set.
+var pairsTpl string = `
+// {{.Pairs}} lists the names of the {{.Type}} constants and the constants in
+// declaration order, e.g. for the options of a select box.
+var {{.Pairs}} = []struct {
+	Name  string
+	Value {{.TypeQual}}{{.Type}}
+}{
+	{{range .Consts}} { {{- printf "%q" .Key}}, {{$.Qual}}{{.Name}}},
+	{{end}}
+}
+`
+
+// infoEntry is a constant described by infosTpl.
+type infoEntry struct {
+	Value
+	Deprecated string // The message of the "Deprecated: " paragraph of the doc comment.
+}
+
+// infosTpl declares a struct type describing a constant and a slice
+// describing all of them, in declaration order, if Infos is set.
+var infosTpl string = `
+// {{.Info}} describes a {{.Type}} constant.
+type {{.Info}} struct {
+	Name       string   // The name of the constant, as encoded.
+	Value      {{.TypeQual}}{{.Type}}
+	Doc        string   // The doc comment of the constant.
+	Groups     []string // The groups of the constant, set by //mapconst:group= directives.
+	Deprecated string   // The deprecation message, if the constant is deprecated.
+}
+
+// {{.Infos}} describes the {{.Type}} constants in declaration order.
+var {{.Infos}} = []{{.Info}}{
+	{{- range .Entries}}
+	{Name: {{printf "%q" .Key}}, Value: {{$.Qual}}{{.Name}}
+		{{- if .Doc}}, Doc: {{printf "%q" .Doc}}{{end}}
+		{{- if .Groups}}, Groups: []string{ {{- range $i, $g := .Groups}}{{if $i}}, {{end}}{{printf "%q" $g}}{{end}}}{{end}}
+		{{- if .Deprecated}}, Deprecated: {{printf "%q" .Deprecated}}{{end}}},
+	{{- end}}
+}
+`
+
+// assertTpl asserts the values of the constants at compile time, if Assert is
+// set: an index out of range breaks the build once a value changes, e.g. as
+// a constant is inserted in an iota sequence without regenerating.
+var assertTpl string = `
+func _() {
+	// An "invalid array index" compiler error signifies that the values of
+	// the {{.Type}} constants changed. Run mapconst again.
+	var x [1]struct{}
+	{{- range .Consts}}
+	_ = x[{{$.Qual}}{{.Name}}-({{.Value}})]
+	{{- end}}
+}
+`
+
+// exhaustiveTpl declares an anchor for the exhaustive linter, if Exhaustive
+// is set: run with -check=switch,map, it reports the map literal once a
+// constant is added without regenerating.
+var exhaustiveTpl string = `
+// This map lists every {{.Type}} value for the exhaustive linter.
+//
+//exhaustive:enforce
+var _ = map[{{.TypeQual}}{{.Type}}]struct{} {
+	{{range .Unique}} {{$.Qual}}{{.Name}}: {},
+	{{end}}
+}
+`
+
+// countTpl declares the number of distinct values, if Count is set.
+var countTpl string = `
+// {{.Count}} is the number of distinct {{.Type}} values.
+const {{.Count}} = {{len .Unique}}
+`
+
+// lookupTpl holds the unexported lookups that generated methods share.
+var lookupTpl string = `
+{{- if .Lazy}}
+// _{{.Type}}_names returns the map of the {{.Type}} values to their names,
+// which is built on first use.
+var _{{.Type}}_names = sync.OnceValue(func() map[{{.Type}}]string {
+	return map[{{.Type}}]string {
+		{{range .Unique}} {{.Name}}:{{printf "%q" .Key}},
+		{{end}}
+	}
+})
+{{else if not .NoAlloc}}
+// _{{.Type}}_names maps the {{.Type}} values to their names.
+var _{{.Type}}_names = map[{{.Type}}]string {
+	{{range .Unique}} {{.Name}}:{{printf "%q" .Key}},
+	{{end}}
+}
+{{end}}
+// _{{.Type}}_fromName returns the {{.Type}} constant named s.
+func _{{.Type}}_fromName(s string) ({{.Type}}, bool) {
+	v, ok := {{.FromName "s"}}
+	return v, ok
+}
+
+// _{{.Type}}_decode returns the {{.Type}} constant named s for decoding.
+{{- if .Default}}
+// Any other s decodes as {{.Default}}.{{end}}
+func _{{.Type}}_decode(s string) ({{.Type}}, bool) {
+	{{- if .Default}}
+	if v, ok := _{{.Type}}_fromName(s); ok {
+		return v, true
+	}
+	return {{.Default}}, true
+	{{- else}}
+	return _{{.Type}}_fromName(s)
+	{{- end}}
+}
+
+// _{{.Type}}_toName returns the name of the {{.Type}} constant v; the first
+// declared if several share its value.
+func _{{.Type}}_toName(v {{.Type}}) (string, bool) {
+	{{- if .NoAlloc}}
+	switch v {
+	{{- range .Unique}}
+	case {{.Name}}:
+		return {{printf "%q" .Key}}, true
+	{{- end}}
+	}
+	return "", false
+	{{- else}}
+	s, ok := _{{.Type}}_names{{if .Lazy}}(){{end}}[v]
+	return s, ok
+	{{- end}}
+}
+`
+
+// Config holds the options of the generated code. The zero value generates
+// a map of the names of the constants to their values per type.
+type Config struct {
+	TrimPrefix string // Prefix trimmed from the constant names to form map keys, unless a TypeSpec sets its own.
+	Transform  string // Transform of the trimmed names to keys, unless a TypeSpec sets its own: none (the default), lower, upper, snake, snake-upper, kebab, kebab-upper or camel.
+	KeyPrefix  string // Prefix added to the transformed keys and the aliases, e.g. "status:", unless a TypeSpec sets its own.
+	KeySuffix  string // Suffix added to the transformed keys and the aliases, unless a TypeSpec sets its own.
+	Lookup     string // How names are looked up: map (the default), switch, perfecthash or binarysearch.
+	Normalize  string // Unicode normalization of the keys: none (the default), nfc or nfkc.
+	Aliases    string // What to do with constants of the same key: error (the default) or first, which keeps the first declared.
+	NoAlloc    bool   // Avoid package-level maps; implies the switch lookup unless perfecthash or binarysearch.
+	Lazy       bool   // Build maps on first use with sync.OnceValue; requires the map lookup.
+	VarName    string // Template of the name lookup identifier, e.g. "{{.Type}}ByName".
+	Private    bool   // Make the generated variables and functions unexported.
+	DocConsts  bool   // List the constants in the doc comment of the name lookup.
+	NameMap    bool   // Also declare <type>Names, a map of the values to their names.
+	ValueMap   bool   // Also declare <type>ByValue, a map of the value literals, e.g. "200" or "us-east-1", to the constants.
+	Deprecated bool   // Also declare <type>Deprecated, a map of the values of deprecated constants to the deprecation messages.
+	Groups     bool   // Also declare <type>Groups, the constants by the groups of their //mapconst:group= directives, and <type>In<group>.
+	Constraint bool   // Also declare <type>Like, the constraint of the types with the underlying type, and Enum, the union of all generated types.
+	Register   bool   // Also register the type with the registry package of mapconst at init.
+	Registry   bool   // Also declare EnumRegistry, a map of the names of all generated types to maps of their names to their constants, and LookupEnum.
+	Pairs      bool   // Also declare <type>Pairs, a slice of the names and constants in declaration order.
+	Infos      bool   // Also declare <type>Info and <type>Infos, describing each constant by its name, value, doc, groups and deprecation.
+	Exhaustive bool   // Also declare a map literal of all the constants for the exhaustive linter to check.
+	Assert     bool   // Also declare compile-time assertions that break the build once the values of the constants change.
+	Count      bool   // Also declare <type>Count, the number of distinct values.
+	I18n       bool   // Also declare <type>I18nKeys, a map of the values to translation keys.
+	MustParse  bool   // Also declare MustParse<type>, which panics for unknown names.
+	Env        bool   // Also declare <type>FromEnv, parsing an environment variable.
+	HTTP       bool   // Also declare <type>FromQuery and <type>FromPath, parsing request parameters, and Invalid<type>Error.
+	Contiguous bool   // Require the values to be 0 to <type>Count-1, e.g. to index arrays; implies Count.
+	Header     string // License or copyright notice put at the top of every output.
+	BuildTags  string // Build constraint of the generated Go code: comma-separated tags or a //go:build expression.
+
+	// TemplateFuncs holds substitution tables, each made available to the
+	// templates as a function of the same name. Besides, templates can use
+	// snake, snakeUpper, kebab, camel, lower, upper, trimprefix, trimsuffix,
+	// quote and receiver.
+	TemplateFuncs map[string]map[string]string
+	Args          string // Arguments recorded in the "Code generated" line, e.g. those of the command line.
+
+	// HeaderTemplate, if set, is the template of the comment lines preceding
+	// the package clause of Go output, in place of the "Code generated"
+	// lines; its data has the fields Package, Command, Args, Version,
+	// Timestamp and Hash, the input hash line. Unless the output keeps a
+	// line starting with // Code generated by "mapconst, mapconst no longer
+	// recognizes it as its own, nor, without Hash, that it is up to date.
+	HeaderTemplate string
+	// Timestamp makes the time of generation available to HeaderTemplate.
+	Timestamp bool
+	// EmitGenerate adds a //go:generate directive running mapconst with
+	// GenerateArgs to the Go output, so that go generate reproduces it.
+	EmitGenerate bool
+	// GenerateArgs are the arguments of the directive of EmitGenerate,
+	// which runs in the directory of the output: those of Args with the
+	// relative paths made relative to that directory. Default Args.
+	GenerateArgs string
+	// Append delimits the code of each type by marker comments, followed
+	// by the "Code generated" line of the command generating it, so that
+	// Merge can keep the code of other types in the existing output.
+	Append bool
+	// I18nPattern is the template of the translation keys, executed per
+	// constant with the fields Type, Name and Key; default
+	// "{{snake .Type}}.{{snake .Key}}", e.g. status.active.
+	I18nPattern string
+	// SkipDeprecated leaves out the constants whose doc comment has a
+	// "Deprecated: " paragraph.
+	SkipDeprecated bool
+
+	Binary       string // Generate MarshalBinary/UnmarshalBinary encoding the constant name or value.
+	Msgpack      bool   // Generate EncodeMsgpack/DecodeMsgpack encoding the constant name.
+	BSON         bool   // Generate MarshalBSONValue/UnmarshalBSONValue encoding the constant name.
+	GQLGen       bool   // Generate MarshalGQL/UnmarshalGQL encoding the constant name.
+	JSON         bool   // Generate MarshalJSON/UnmarshalJSON encoding the constant name.
+	SQLNull      bool   // Generate Null<type>, a nullable wrapper implementing sql.Scanner and driver.Valuer with the constant name.
+	Validator    bool   // Generate Register<type>Validation for github.com/go-playground/validator and <type>OneOf, the values of its oneof tag.
+	TestGen      bool   // Generate Random<type> and a Generate method implementing testing/quick.Generator.
+	Navigation   bool   // Generate <type>Min, <type>Max and the methods Next, Prev and Ordinal; requires contiguous integer values.
+	JSONNumeric  bool   // Let UnmarshalJSON also accept the value of the constant, e.g. during a migration from numbers to names.
+	ProtoPackage string // Protobuf package of the Proto output; default the Go package name.
+	ProtoGo      string // Import path of the Go code generated from the Proto output; generates conversions.
+	ProtoEnum    string // Existing protobuf enum, as import path and type, e.g. example.com/pb.Status; generates conversions matching names.
+	Pgx          bool   // Generate Register<type> for github.com/jackc/pgx, mapping the Postgres enum type of the SQL output by ScanText and TextValue.
+	Gorm         bool   // Generate GormDataType and GormDBDataType, and Scan and Value storing the constant name, for GORM models.
+	Ent          bool   // Generate Values for entgo.io enum fields, Scan and Value storing the constant name, and <type>Validator.
+	CBOR         bool   // Generate MarshalCBOR/UnmarshalCBOR for github.com/fxamacker/cbor encoding the constant name as a text string.
+	CBORNumeric  bool   // Let UnmarshalCBOR also accept the value of the constant as a CBOR number.
+	TOML         bool   // Generate MarshalTOML/UnmarshalTOML of the go-toml and BurntSushi/toml interfaces encoding the constant name.
+	XML          bool   // Generate MarshalXML/UnmarshalXML and MarshalXMLAttr/UnmarshalXMLAttr encoding the constant name.
+	Slog         bool   // Generate LogValue implementing slog.LogValuer with the constant name.
+	Lenient      bool   // Decode unknown names as the constant marked by a //mapconst:default directive instead of failing; requires a method decoding names.
+	Strict       bool   // Fail types with a method to generate already declared by hand, instead of skipping the method.
+
+	Tests      bool // Include tests of the generated code in the Tests output.
+	Fuzz       bool // Include fuzz targets of the generated parsing in the Tests output.
+	Benchmarks bool // Include benchmarks of the lookups in the Tests output.
+
+	// GOOS and GOARCH select the platform-specific files of the package;
+	// they default to those of go/build's Default context, which also
+	// resolves the packages it imports.
+	GOOS, GOARCH string
+	// Cgo runs cgo on packages importing "C", so constants defined by C
+	// resolve. It needs a C compiler.
+	Cgo bool
+
+	// Logf, if set, receives progress details: the files parsed and which
+	// constants are generated or skipped, and why.
+	Logf func(format string, args ...interface{})
+	// Warnf, if set, receives warnings.
+	Warnf func(format string, args ...interface{})
+}
+
+// validate reports inconsistent options.
+func (c *Config) validate() error {
+	switch c.Binary {
+	case "", "name", "value":
+	default:
+		return fmt.Errorf("invalid binary encoding %q; must be name or value", c.Binary)
+	}
+	switch c.Lookup {
+	case "", "map", "switch", "perfecthash", "binarysearch":
+	default:
+		return fmt.Errorf("invalid lookup %q; must be map, switch, perfecthash or binarysearch", c.Lookup)
+	}
+	switch c.Aliases {
+	case "", "error", "first":
+	default:
+		return fmt.Errorf("invalid aliases %q; must be error or first", c.Aliases)
+	}
+	switch c.Normalize {
+	case "", "none", "nfc", "nfkc":
+	default:
+		return fmt.Errorf("invalid normalization %q; must be none, nfc or nfkc", c.Normalize)
+	}
+	for name := range c.TemplateFuncs {
+		if !token.IsIdentifier(name) {
+			return fmt.Errorf("invalid template function name %q", name)
+		}
+	}
+	if c.JSONNumeric && !c.JSON {
+		return errors.New("the numeric fallback of JSON requires JSON")
+	}
+	if c.ProtoEnum != "" {
+		if c.ProtoGo != "" {
+			return errors.New("conversions to a protobuf enum cannot be combined with those of ProtoGo")
+		}
+		if importPath, _, typ := c.protoEnum(); importPath == "" || !token.IsIdentifier(typ) {
+			return fmt.Errorf("invalid protobuf enum %q; must be an import path and a type, e.g. example.com/pb.Status", c.ProtoEnum)
+		}
+	}
+	if c.Lenient && !c.decodes() {
+		return errors.New("Lenient requires methods decoding names, such as those of JSON")
+	}
+	if c.CBORNumeric && !c.CBOR {
+		return errors.New("the numeric fallback of CBOR requires CBOR")
+	}
+	if (c.NameMap || c.ValueMap || c.I18n || c.Deprecated || c.Groups || c.Exhaustive || c.Registry) && c.NoAlloc {
+		return errors.New("maps of values cannot be combined with NoAlloc")
+	}
+	if c.Lazy && (c.lookup() != "map" || c.NoAlloc) {
+		return errors.New("lazy maps require the map lookup and cannot be combined with NoAlloc")
+	}
+	if c.Append && (c.Registry || c.Constraint) {
+		return errors.New("Append cannot be combined with Registry or Constraint, which cover all the types of the file")
+	}
+	return nil
+}
+
+// lookup returns how names are looked up, with defaults applied.
+func (c *Config) lookup() string {
+	switch {
+	case c.NoAlloc && (c.Lookup == "" || c.Lookup == "map"):
+		return "switch"
+	case c.Lookup == "":
+		return "map"
+	}
+	return c.Lookup
+}
+
+// verbose reports whether progress details are logged.
+func (c *Config) verbose() bool {
+	return c.Logf != nil
+}
+
+func (c *Config) logf(format string, args ...interface{}) {
+	if c.Logf != nil {
+		c.Logf(format, args...)
+	}
+}
+
+func (c *Config) warnf(format string, args ...interface{}) {
+	if c.Warnf != nil {
+		c.Warnf(format, args...)
+	}
+}
+
+// abort carries an error ending generation out of the depths of the
+// generator. The exported entry points recover it with catch.
+type abort struct {
+	err error
+}
+
+// fatalf ends generation with the formatted error.
+func fatalf(format string, args ...interface{}) {
+	panic(abort{fmt.Errorf(format, args...)})
+}
+
+// catch recovers the error of a fatalf into *err.
+func catch(err *error) {
+	if e := recover(); e != nil {
+		a, ok := e.(abort)
+		if !ok {
+			panic(e)
+		}
+		*err = a.err
+	}
+}
+
+// Generate returns the Go source declaring, in the package pkg itself, the
+// name maps and the other code cfg asks for of the types, each as accepted
+// by ParseTypeSpecs. The package is a directory or an import path. A nil
+// cfg is the zero Config.
+func Generate(pkg string, types []string, cfg *Config) ([]byte, error) {
+	if cfg == nil {
+		cfg = new(Config)
+	}
+	g, err := Load([]string{pkg}, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if g.ReadOnly() {
+		return nil, fmt.Errorf("package %s is outside the current module; use Load and Qualify", g.ImportPath())
+	}
+	for _, t := range types {
+		specs, err := ParseTypeSpecs(t, cfg.TrimPrefix, cfg.Transform)
+		if err != nil {
+			return nil, err
+		}
+		for _, spec := range specs {
+			if err := g.Generate(spec); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return g.Source(g.Name())
+}
+
+// Load parses the package of the constants: the package in a directory or
+// of an import path, given as the only argument, or the package made of the
+// named files. Import paths are resolved relative to the current directory,
+// so module-aware lookups (go.mod requirements, replacements) apply just as
+// they do for the go command.
+func Load(args []string, cfg *Config) (g *Generator, err error) {
+	defer catch(&err)
+	g = newGenerator(cfg)
+	if len(args) == 0 {
+		return nil, errors.New("no package to load")
+	}
+	if len(args) == 1 && isDirectory(args[0]) {
+		g.parsePackageDir(args[0])
+	} else if len(args) == 1 && isImportPath(args[0]) {
+		g.parsePackageImport(args[0])
+	} else {
+		g.parsePackageFiles(args)
+	}
+	return g, nil
+}
+
+// LoadSource parses the package of the constants from the source of a single
+// file, which may be read from anywhere; name is used in messages. The
+// package is assumed to reside in the current directory.
+func LoadSource(name string, src []byte, cfg *Config) (g *Generator, err error) {
+	defer catch(&err)
+	g = newGenerator(cfg)
+	g.parsePackage(".", []string{name}, src)
+	g.pkg.importPath = importPathOf(".", nil)
+	return g, nil
+}
+
+// newGenerator returns a Generator using the validated configuration.
+func newGenerator(cfg *Config) *Generator {
+	if err := cfg.validate(); err != nil {
+		panic(abort{err})
+	}
+	g := &Generator{cfg: cfg, ctxt: build.Default}
+	if cfg.GOOS != "" {
+		g.ctxt.GOOS = cfg.GOOS
+	}
+	if cfg.GOARCH != "" {
+		g.ctxt.GOARCH = cfg.GOARCH
+	}
+	return g
+}
+
+// Dir returns the directory of the loaded package.
+func (g *Generator) Dir() string {
+	return g.pkg.dir
+}
+
+// Name returns the name of the loaded package.
+func (g *Generator) Name() string {
+	return g.pkg.name
+}
+
+// ImportPath returns the import path of the loaded package, or "" if it is
+// unknown.
+func (g *Generator) ImportPath() string {
+	return g.pkg.importPath
+}
+
+// ReadOnly reports whether the loaded package, found by import path, lies
+// outside the module of the current directory, e.g. in GOROOT or the module
+// cache. Code for it belongs into another package; see Qualify.
+func (g *Generator) ReadOnly() bool {
+	return g.pkg.readOnly
+}
+
+// Types returns the names of the types generated so far.
+func (g *Generator) Types() []string {
+	names := make([]string, len(g.types))
+	for i, data := range g.types {
+		names[i] = data.TypeQual + data.Type
+	}
+	return names
+}
+
+// ConstTypes returns the names of the types of the constants of the loaded
+// package, sorted; types declared with an alias are listed once, by the
+// name of their declaration if it is in the package.
+func (g *Generator) ConstTypes() []string {
+	var names []string
+	var seen []types.Type
+next:
+	for _, tc := range g.pkg.consts {
+		name := tc.name
+		if tc.typ != nil {
+			for _, t := range seen {
+				if types.Identical(t, tc.typ) {
+					continue next
+				}
+			}
+			seen = append(seen, tc.typ)
+			if named, ok := tc.typ.(*types.Named); ok && named.Obj().Pkg() == g.pkg.typesPkg {
+				name = named.Obj().Name()
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Consts returns the constants of the generated type, named as by Types, in
+// the order of declaration, with their Lit set if the value is resolved.
+func (g *Generator) Consts(typeName string) []Value {
+	for _, data := range g.types {
+		if data.TypeQual+data.Type != typeName {
+			continue
+		}
+		consts := make([]Value, len(data.Consts))
+		for i, c := range data.Consts {
+			consts[i] = c
+			consts[i].Lit, _ = literal(c.Value)
+		}
+		return consts
+	}
+	return nil
+}
+
+// OutputBase returns the base of the default output file names for the
+// type: its name, lower-cased, with the dot of a qualified name replaced.
+// The Go output of the mapconst command is named OutputBase+"_mapconst.go".
+func OutputBase(typeName string) string {
+	return strings.ToLower(strings.Replace(typeName, ".", "_", -1))
+}
+
+// isDirectory reports whether the named file is a directory.
+func isDirectory(name string) bool {
+	info, err := os.Stat(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false
+		}
+		fatalf("%s", err)
+	}
+	return info.IsDir()
+}
+
+// isImportPath reports whether the argument should be resolved as an import
+// path rather than read as a file: it names neither a Go file nor anything
+// existing on disk.
+func isImportPath(name string) bool {
+	if strings.HasSuffix(name, ".go") {
+		return false
+	}
+	_, err := os.Stat(name)
+	return os.IsNotExist(err)
+}
+
+// importPathOf returns the import path of the package in directory. Outside
+// GOPATH, go/build cannot tell, so it is derived from the enclosing go.mod.
+func importPathOf(directory string, pkg *build.Package) string {
+	if pkg != nil && pkg.ImportPath != "" && !build.IsLocalImport(pkg.ImportPath) {
+		return pkg.ImportPath
+	}
+	abs, err := filepath.Abs(directory)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	root := moduleRoot(abs)
+	if root == "" {
+		return ""
+	}
+	data, err := ioutil.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		fatalf("%s", err)
+	}
+	modPath := modulePath(data)
+	if modPath == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	return path.Join(modPath, filepath.ToSlash(rel))
+}
+
+// moduleRoot returns the directory of the go.mod enclosing the absolute
+// directory, or "" if there is none.
+func moduleRoot(abs string) string {
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+// isOutsideModule reports whether the package found by go/build lies outside
+// the module of the current directory, and the other modules of its go.work
+// workspace, e.g. in GOROOT, the module cache or the vendor directory. Such
+// a package is only read; the output goes to the current directory.
+func isOutsideModule(pkg *build.Package, wd string) bool {
+	if pkg.Goroot {
+		return true
+	}
+	root := moduleRoot(wd)
+	if root == "" {
+		return false
+	}
+	if within(filepath.Join(root, "vendor"), pkg.Dir) {
+		return true
+	}
+	for _, dir := range append([]string{root}, workspaceModules(wd)...) {
+		if within(dir, pkg.Dir) {
+			return false
+		}
+	}
+	return true
+}
+
+// within reports whether path lies in the directory.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// modulePath returns the module path declared in the go.mod content.
+func modulePath(mod []byte) string {
+	for _, line := range strings.Split(string(mod), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// ErrNoConsts reports a type without any constants to generate.
+var ErrNoConsts = errors.New("no const defined")
+
+// TypeError records why a type could not be generated.
+type TypeError struct {
+	Type string
+	Err  error
+}
+
+func (e *TypeError) Error() string {
+	return "type " + e.Type + ": " + e.Err.Error()
+}
+
+func (e *TypeError) Unwrap() error {
+	return e.Err
+}
+
+// Generator holds the state of the analysis. Primarily used to buffer
+// the output for goimports.
+type Generator struct {
+	cfg     *Config           // Options of the generated code.
+	ctxt    build.Context     // Context locating the files of the package.
+	buf     bytes.Buffer      // Accumulated output.
+	pkg     *Package          // Package we are scanning.
+	qual    string            // Qualifier for source identifiers, empty when generating into the source package.
+	imports map[string]string // Import paths of package names the output may refer to.
+	types   []*mapConstData   // The generated types, for emitters of other languages.
+	merged  string            // The sections of other types kept by Merge, part of the input hash.
+}
+
+// execute applies the named template to data, appending to the output.
+// The shared templates of constListTpl are available to it.
+func (g *Generator) execute(name, text string, data interface{}) {
+	tpl := template.Must(template.New(name).Funcs(g.cfg.funcs()).Parse(text))
+	template.Must(tpl.Parse(constListTpl))
+	if err := tpl.Execute(&g.buf, data); err != nil {
+		fatalf("executing %s: %s", name, err)
+	}
+}
+
+// Qualify makes the generator refer to the source package from outside, for
+// code generated into another package: identifiers get qualified by the
+// package name and unexported constants, which cannot be referenced, are
+// left out. It must be called before Generate.
+func (g *Generator) Qualify() error {
+	if g.pkg.importPath == "" {
+		return fmt.Errorf("cannot determine import path of package %s in %s", g.pkg.name, g.pkg.dir)
+	}
+	g.qual = g.pkg.name + "."
+	g.addImport(g.pkg.name, g.pkg.importPath)
+	return nil
+}
+
+func (g *Generator) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// File holds a single parsed file and associated data.
+type File struct {
+	cfg  *Config   // Options of the generated code.
+	pkg  *Package  // Package to which this file belongs.
+	file *ast.File // Parsed AST.
+	// The specs of the const declarations.
+	specs []constSpec
+	// Whether mapconst generated the file, so that its declarations are
+	// to be replaced.
+	generated bool
+}
+
+type Package struct {
+	fset       *token.FileSet
+	dir        string
+	name       string
+	importPath string
+	defs       map[*ast.Ident]types.Object
+	files      []*File
+	typesPkg   *types.Package
+	readOnly   bool          // Whether the package lies outside the current module.
+	consts     []*typeConsts // The typed constants of the files, by type.
+}
+
+// typeConsts holds the constants declared with a type, in declaration order.
+type typeConsts struct {
+	name   string     // The name of the type as spelled by the first declaration, e.g. "T" or "pkg.T".
+	typ    types.Type // The type, with aliases resolved; nil if it was not type-checked.
+	consts []Value
+}
+
+// parsePackageDir parses the package residing in the directory.
+func (g *Generator) parsePackageDir(directory string) {
+	pkg, err := g.ctxt.ImportDir(directory, 0)
+	if err != nil {
+		fatalf("cannot process directory %s: %s", directory, err)
+	}
+	g.parseBuildPackage(directory, pkg)
+}
+
+// parsePackageImport parses the package named by the import path, resolved
+// relative to the current directory.
+func (g *Generator) parsePackageImport(importPath string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		fatalf("%s", err)
+	}
+	pkg, err := g.ctxt.Import(importPath, wd, 0)
+	if err != nil {
+		fatalf("cannot import package %s: %s", importPath, err)
+	}
+	g.parseBuildPackage(pkg.Dir, pkg)
+	g.pkg.readOnly = isOutsideModule(pkg, wd)
+}
+
+// parseBuildPackage parses the Go files of a package located by go/build.
+func (g *Generator) parseBuildPackage(directory string, pkg *build.Package) {
+	var names []string
+	names = append(names, pkg.GoFiles...)
+	names = append(names, pkg.CgoFiles...)
+	// TODO: Need to think about constants in test files. Maybe write type_string_test.go
+	// in a separate pass? For later.
+	// names = append(names, pkg.TestGoFiles...) // These are also in the "foo" package.
+	names = append(names, pkg.SFiles...)
+	names = prefixDirectory(directory, names)
+	if g.cfg.Cgo && len(pkg.CgoFiles) > 0 {
+		names = cgoFiles(&g.ctxt, directory)
+	}
+	g.parsePackage(directory, names, nil)
+	g.pkg.importPath = importPathOf(directory, pkg)
+}
+
+// parsePackageFiles parses the package occupying the named files.
+func (g *Generator) parsePackageFiles(names []string) {
+	g.parsePackage(filepath.Dir(names[0]), names, nil)
+	g.pkg.importPath = importPathOf(g.pkg.dir, nil)
+}
+
+// prefixDirectory places the directory name on the beginning of each name in the list.
+func prefixDirectory(directory string, names []string) []string {
+	if directory == "." {
+		return names
+	}
+	ret := make([]string, len(names))
+	for i, name := range names {
+		ret[i] = filepath.Join(directory, name)
+	}
+	return ret
+}
+
+// parsePackage analyzes the single package constructed from the named files.
+// If text is non-nil, it is a string to be used instead of the content of the file,
+// to be used for testing. parsePackage aborts if there is an error.
+func (g *Generator) parsePackage(directory string, names []string, text interface{}) {
+	g.pkg = new(Package)
+	fs := token.NewFileSet()
+	var goFiles []string
+	for _, name := range names {
+		// Files in the build cache, such as those cgo outputs, have no
+		// extension; anything else must be a Go file.
+		if ext := filepath.Ext(name); ext != ".go" && ext != "" {
+			continue
+		}
+		goFiles = append(goFiles, name)
+	}
+	// Parse the files concurrently; the file set is safe for concurrent use.
+	files := make([]*File, len(goFiles))
+	errs := make([]error, len(goFiles))
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, name := range goFiles {
+		g.cfg.logf("parsing %s", name)
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer func() { <-sem; wg.Done() }()
+			parsedFile, err := parser.ParseFile(fs, name, text, parser.ParseComments)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			files[i] = &File{
+				cfg:       g.cfg,
+				file:      parsedFile,
+				pkg:       g.pkg,
+				specs:     constSpecs(parsedFile),
+				generated: isGenerated(parsedFile),
+			}
+		}(i, name)
+	}
+	wg.Wait()
+	astFiles := make([]*ast.File, len(files))
+	for i, file := range files {
+		if errs[i] != nil {
+			fatalf("parsing package: %s: %s", goFiles[i], errs[i])
+		}
+		astFiles[i] = file.file
+	}
+	if len(astFiles) == 0 {
+		fatalf("%s: no buildable Go files", directory)
+	}
+	g.pkg.fset = fs
+	g.pkg.name = astFiles[0].Name.Name
+	g.pkg.files = files
+	g.pkg.dir = directory
+	// Type check the package.
+	g.pkg.check(fs, astFiles, g.ctxt.GOARCH)
+	// Collect the constants of all types at once.
+	for _, file := range files {
+		file.collect()
+	}
+}
+
+// check type-checks the package so constant values can be resolved. Errors
+// are tolerated: they mostly stem from code referring to declarations that
+// have yet to be generated, and the affected constants just lack values.
+func (pkg *Package) check(fs *token.FileSet, astFiles []*ast.File, goarch string) {
+	pkg.defs = make(map[*ast.Ident]types.Object)
+	config := types.Config{
+		Importer:    importer.ForCompiler(fs, "source", nil),
+		FakeImportC: true,
+		Error:       func(error) {},
+		Sizes:       types.SizesFor("gc", goarch),
+	}
+	info := &types.Info{
+		Defs: pkg.defs,
+	}
+	typesPkg, _ := config.Check(pkg.dir, fs, astFiles, info)
+	pkg.typesPkg = typesPkg
+}
+
+// imported returns the package the source imports under the name, or nil.
+func (pkg *Package) imported(name string) *types.Package {
+	if pkg.typesPkg == nil {
+		return nil
+	}
+	for _, imp := range pkg.typesPkg.Imports() {
+		if imp.Name() == name {
+			return imp
+		}
+	}
+	return nil
+}
+
+// importPathOf returns the import path of the package the source imports
+// under the name, or "" if there is none.
+func (pkg *Package) importPathOf(name string) string {
+	if imp := pkg.imported(name); imp != nil {
+		return imp.Path()
+	}
+	return ""
+}
+
+// lookupType returns the named type, which may be qualified by the name of
+// an imported package, with aliases resolved, or nil if it is unknown.
+func (pkg *Package) lookupType(typeName string) types.Type {
+	if pkg.typesPkg == nil {
+		return nil
+	}
+	scope := pkg.typesPkg.Scope()
+	if i := strings.Index(typeName, "."); i >= 0 {
+		imp := pkg.imported(typeName[:i])
+		if imp == nil {
+			return nil
+		}
+		scope, typeName = imp.Scope(), typeName[i+1:]
+	}
+	obj, ok := scope.Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	return unalias(obj.Type())
+}
+
+// underlying returns the underlying basic type of the named type, or nil if
+// it is unknown or not basic.
+func (pkg *Package) underlying(typeName string) *types.Basic {
+	typ := pkg.lookupType(typeName)
+	if typ == nil {
+		return nil
+	}
+	basic, _ := typ.Underlying().(*types.Basic)
+	return basic
+}
+
+// Generate adds the code of the type to the output. If the type cannot be
+// generated, the output is left untouched and the *TypeError returned
+// tells why; ErrNoConsts if there are no constants of the type.
+func (g *Generator) Generate(spec TypeSpec) (err error) {
+	typeName := spec.Name
+	// Leave the output untouched if the type turns out not to be generable.
+	mark, ntypes := g.buf.Len(), len(g.types)
+	defer func() {
+		if err != nil {
+			g.buf.Truncate(mark)
+			g.types = g.types[:ntypes]
+			err = &TypeError{Type: typeName, Err: err}
+		} else if g.cfg.Append {
+			g.Printf("\n%s%s\n", sectionEnd, typeName)
+		}
+	}()
+	defer catch(&err)
+	if g.cfg.Append {
+		// The header records the last command only; each section records
+		// its own, so that the file is known to hold the types of all.
+		g.Printf("\n%s%s\n%s\n", sectionBegin, typeName, g.cfg.generatedLine("//"))
+	}
+
+	consts := g.constsOf(typeName)
+
+	if g.cfg.SkipDeprecated {
+		kept := consts[:0]
+		for _, c := range consts {
+			if deprecation(c.Doc) != "" {
+				g.cfg.logf("%s: %s skipped: deprecated", g.pkg.fset.Position(c.pos), c.Name)
+				continue
+			}
+			kept = append(kept, c)
+		}
+		consts = kept
+	}
+	if len(consts) == 0 {
+		return ErrNoConsts
+	}
+	keyPrefix, keySuffix := spec.affixes(g.cfg)
+	for i := range consts {
+		if consts[i].Key, err = Key(consts[i].Name, spec, g.cfg); err != nil {
+			return err
+		}
+		aliases := make([]string, len(consts[i].Aliases))
+		for j, alias := range consts[i].Aliases {
+			aliases[j] = g.cfg.normalize(keyPrefix + alias + keySuffix)
+		}
+		consts[i].Aliases = aliases
+	}
+	if consts, err = g.uniqueKeys(consts); err != nil {
+		return err
+	}
+	if g.cfg.verbose() {
+		names := make([]string, len(consts))
+		for i, c := range consts {
+			names[i] = c.Name
+		}
+		g.cfg.logf("type %s: generating %s", typeName, strings.Join(names, ", "))
+	}
+	data := &mapConstData{
+		Type:     typeName,
+		Qual:     g.qual,
+		TypeQual: g.qual,
+		Consts:   consts,
+		Entries:  lookupEntries(consts),
+		Unique:   uniqueValues(consts),
+	}
+	// A qualified type, pkg.Kind, is declared in a package the source
+	// imports, which the output has to import as well.
+	external := false
+	if i := strings.Index(typeName, "."); i >= 0 {
+		external = true
+		data.Type, data.TypeQual = typeName[i+1:], typeName[:i+1]
+		importPath := g.pkg.importPathOf(typeName[:i])
+		if importPath == "" {
+			return fmt.Errorf("cannot resolve package %s", typeName[:i])
+		}
+		g.addImport(typeName[:i], importPath)
+	}
+	// So is a local alias of such a type.
+	if named, ok := g.pkg.lookupType(typeName).(*types.Named); ok && named.Obj().Pkg() != g.pkg.typesPkg {
+		external = true
+	}
+	if g.qual != "" && !ast.IsExported(data.Type) {
+		return errors.New("unexported type cannot be used from another package")
+	}
+	basic := g.pkg.underlying(typeName)
+	if basic != nil {
+		data.Underlying = basic.Name()
+		data.Unsigned = basic.Info()&types.IsUnsigned != 0
+	}
+	g.types = append(g.types, data)
+	data.NoAlloc = g.cfg.NoAlloc
+	data.Lazy = g.cfg.Lazy
+	data.Lookup = g.cfg.lookup()
+	data.DocConsts = g.cfg.DocConsts
+	data.Var = g.cfg.varName(data)
+	data.Blob = len(consts) > blobThreshold && !data.NoAlloc &&
+		(data.Lookup == "map" || g.cfg.NameMap)
+	if data.Blob {
+		g.execute("blobTpl", blobTpl, data)
+	}
+	switch data.Lookup {
+	case "map":
+		g.execute("mapConstTpl", mapConstTpl, data)
+	case "switch":
+		g.execute("switchLookupTpl", switchLookupTpl, data)
+	case "perfecthash":
+		data.Hash = newPerfectHash(data.Entries)
+		g.execute("perfectHashLookupTpl", perfectHashLookupTpl, data)
+	case "binarysearch":
+		sorted := append([]Value(nil), data.Entries...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+		g.execute("binarySearchLookupTpl", binarySearchLookupTpl, struct {
+			*mapConstData
+			Sorted []Value
+		}{data, sorted})
+	}
+	if g.cfg.NameMap {
+		data.Names = g.cfg.ident(data.Type + "Names")
+		g.execute("namesTpl", namesTpl, data)
+	}
+	if g.cfg.ValueMap {
+		for i := range data.Unique {
+			c := &data.Unique[i]
+			lit, ok := literal(c.Value)
+			if !ok {
+				return fmt.Errorf("value of %s cannot be resolved", c.Name)
+			}
+			c.Lit = lit
+		}
+		data.Values = g.cfg.ident(data.Type + "ByValue")
+		g.execute("valuesTpl", valuesTpl, data)
+	}
+	if g.cfg.Deprecated {
+		// Values shared with a constant in use are not deprecated.
+		current := make(map[string]bool)
+		for _, c := range consts {
+			if c.Value != nil && deprecation(c.Doc) == "" {
+				current[c.Value.ExactString()] = true
+			}
+		}
+		var entries []Value
+		for _, c := range data.Unique {
+			msg := deprecation(c.Doc)
+			if msg == "" || c.Value == nil || current[c.Value.ExactString()] {
+				continue
+			}
+			c.Key = msg
+			entries = append(entries, c)
+		}
+		g.execute("deprecatedTpl", deprecatedTpl, struct {
+			*mapConstData
+			Deprecated string
+			Entries    []Value
+		}{data, g.cfg.ident(data.Type + "Deprecated"), entries})
+	}
+	if g.cfg.Groups {
+		var groups []group
+		index := make(map[string]int)
+		for _, c := range consts {
+			for _, name := range c.Groups {
+				i, ok := index[name]
+				if !ok {
+					fn := g.cfg.ident(data.Type + "In" + exportedName(name))
+					if !token.IsIdentifier(fn) {
+						return fmt.Errorf("group %q of %s does not form an identifier", name, c.Name)
+					}
+					i = len(groups)
+					index[name] = i
+					groups = append(groups, group{Name: name, Func: fn})
+				}
+				groups[i].Consts = append(groups[i].Consts, c)
+			}
+		}
+		sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+		for i := range groups {
+			groups[i].Unique = uniqueValues(groups[i].Consts)
+		}
+		g.execute("groupsTpl", groupsTpl, struct {
+			*mapConstData
+			Var    string
+			Groups []group
+		}{data, g.cfg.ident(data.Type + "Groups"), groups})
+	}
+	if g.cfg.Constraint {
+		g.execute("likeTpl", likeTpl, struct {
+			*mapConstData
+			Like string
+		}{data, g.cfg.ident(data.Type + "Like")})
+	}
+	if g.cfg.Register {
+		path := g.pkg.importPath
+		if path == "" {
+			path = g.pkg.name
+		}
+		g.addImport("registry", "github.com/empirefox/mapconst/registry")
+		g.execute("registerTpl", registerTpl, struct {
+			*mapConstData
+			Path string
+		}{data, path + "." + data.Type})
+	}
+	if g.cfg.Pairs {
+		g.execute("pairsTpl", pairsTpl, struct {
+			*mapConstData
+			Pairs string
+		}{data, g.cfg.ident(data.Type + "Pairs")})
+	}
+	if g.cfg.Infos {
+		entries := make([]infoEntry, len(consts))
+		for i, c := range consts {
+			entries[i] = infoEntry{c, deprecation(c.Doc)}
+		}
+		g.execute("infosTpl", infosTpl, struct {
+			*mapConstData
+			Info, Infos string
+			Entries     []infoEntry
+		}{data, g.cfg.ident(data.Type + "Info"), g.cfg.ident(data.Type + "Infos"), entries})
+	}
+	if g.cfg.Contiguous {
+		lo, _, err := valueRange(data.Unique)
+		if err != nil {
+			return err
+		}
+		if constant.Sign(lo.Value) != 0 {
+			return fmt.Errorf("values are not contiguous from 0: the least is %s = %s", lo.Name, lo.Value)
+		}
+	}
+	if g.cfg.Assert {
+		for _, c := range consts {
+			if c.Value == nil || c.Value.Kind() != constant.Int {
+				return fmt.Errorf("compile-time assertions require integer values; %s is not", c.Name)
+			}
+		}
+		g.execute("assertTpl", assertTpl, data)
+	}
+	if g.cfg.Exhaustive {
+		g.execute("exhaustiveTpl", exhaustiveTpl, data)
+	}
+	if g.cfg.Count || g.cfg.Contiguous {
+		data.Count = g.cfg.ident(data.Type + "Count")
+		g.execute("countTpl", countTpl, data)
+	}
+	if g.cfg.I18n {
+		entries := make([]Value, len(data.Unique))
+		for i, c := range data.Unique {
+			entries[i] = c
+			entries[i].Key = g.cfg.i18nKey(data.Type, c)
+		}
+		g.execute("i18nTpl", i18nTpl, struct {
+			*mapConstData
+			I18nKeys string
+			Entries  []Value
+		}{data, g.cfg.ident(data.Type + "I18nKeys"), entries})
+	}
+	if g.cfg.MustParse {
+		keys := make([]string, len(consts))
+		for i, c := range consts {
+			keys[i] = c.Key
+		}
+		g.execute("mustParseTpl", mustParseTpl, struct {
+			*mapConstData
+			MustParse, Valid string
+		}{data, g.cfg.ident("MustParse" + data.Type), strings.Join(keys, ", ")})
+	}
+	if g.cfg.Env {
+		g.execute("envTpl", envTpl, struct {
+			*mapConstData
+			FromEnv string
+		}{data, g.cfg.ident(data.Type + "FromEnv")})
+	}
+	if g.cfg.HTTP {
+		g.execute("httpTpl", httpTpl, struct {
+			*mapConstData
+			Error, FromQuery, FromPath string
+		}{data, g.cfg.ident("Invalid" + data.Type + "Error"), g.cfg.ident(data.Type + "FromQuery"), g.cfg.ident(data.Type + "FromPath")})
+	}
+
+	if !g.cfg.wantMethods() {
+		return nil
+	}
+	// Methods can only be declared in the package of their receiver.
+	if g.qual != "" || external {
+		return errors.New("cannot generate methods outside the package of the type")
+	}
+	if basic == nil {
+		return errors.New("cannot resolve the underlying type")
+	}
+	if g.cfg.Lenient {
+		for _, c := range consts {
+			switch {
+			case !c.Default:
+			case data.Default != "":
+				return fmt.Errorf("both %s and %s are marked //mapconst:default", data.Default, c.Name)
+			default:
+				data.Default = c.Name
+			}
+		}
+		if data.Default == "" {
+			return errors.New("no constant is marked //mapconst:default for -lenient")
+		}
+	}
+	g.execute("lookupTpl", lookupTpl, data)
+	// Methods written by hand take precedence over generated ones.
+	declared := g.handWrittenMethods(typeName)
+	want := func(methods ...string) bool {
+		for _, m := range methods {
+			if !declared[m] {
+				continue
+			}
+			if g.cfg.Strict {
+				fatalf("method %s is already declared", m)
+			}
+			g.cfg.warnf("type %s: method %s is already declared; skipping %s", typeName, m, strings.Join(methods, ", "))
+			data.skipped = append(data.skipped, methods...)
+			return false
+		}
+		return true
+	}
+	switch g.cfg.Binary {
+	case "name":
+		if want("MarshalBinary", "UnmarshalBinary") {
+			g.execute("binaryNameTpl", binaryNameTpl, data)
+		}
+	case "value":
+		if basic.Info()&types.IsInteger == 0 {
+			return errors.New("binary encoding of values requires an integer type")
+		}
+		if want("MarshalBinary", "UnmarshalBinary") {
+			g.execute("binaryValueTpl", binaryValueTpl, data)
+		}
+	}
+	if g.cfg.Msgpack && want("EncodeMsgpack", "DecodeMsgpack") {
+		g.addImport("msgpack", "github.com/vmihailenco/msgpack/v5")
+		g.execute("msgpackTpl", msgpackTpl, data)
+	}
+	if g.cfg.BSON && want("MarshalBSONValue", "UnmarshalBSONValue") {
+		g.addImport("bsoncore", "go.mongodb.org/mongo-driver/x/bsonx/bsoncore")
+		g.addImport("bsontype", "go.mongodb.org/mongo-driver/bson/bsontype")
+		g.execute("bsonTpl", bsonTpl, data)
+	}
+	if g.cfg.GQLGen && want("MarshalGQL", "UnmarshalGQL") {
+		g.execute("gqlgenTpl", gqlgenTpl, data)
+	}
+	if g.cfg.SQLNull {
+		g.execute("sqlNullTpl", sqlNullTpl, data)
+	}
+	if g.cfg.Validator {
+		keys := make([]string, len(data.Consts))
+		for i, c := range data.Consts {
+			keys[i] = c.Key
+			if strings.ContainsAny(c.Key, " '") {
+				keys[i] = "'" + c.Key + "'"
+			}
+		}
+		g.addImport("validator", "github.com/go-playground/validator/v10")
+		g.execute("validatorTpl", validatorTpl, struct {
+			*mapConstData
+			Tag, OneOf, OneOfName, Register string
+		}{data, strings.ToLower(snake(typeName)), strings.Join(keys, " "),
+			g.cfg.ident(typeName + "OneOf"), g.cfg.ident("Register" + typeName + "Validation")})
+	}
+	if (g.cfg.Ent || g.cfg.Gorm) && want("Scan", "Value") {
+		g.execute("sqlValueTpl", sqlValueTpl, data)
+	}
+	if g.cfg.Pgx && want("ScanText", "TextValue") {
+		g.addImport("pgx", "github.com/jackc/pgx/v5")
+		g.addImport("pgtype", "github.com/jackc/pgx/v5/pgtype")
+		g.execute("pgxTpl", pgxTpl, struct {
+			*mapConstData
+			PgType, Register string
+		}{data, strings.ToLower(snake(typeName)), g.cfg.ident("Register" + typeName)})
+	}
+	if g.cfg.Gorm && want("GormDataType", "GormDBDataType") {
+		size := 1
+		for _, c := range data.Consts {
+			if n := utf8.RuneCountInString(c.Key); n > size {
+				size = n
+			}
+		}
+		g.addImport("gorm", "gorm.io/gorm")
+		g.addImport("schema", "gorm.io/gorm/schema")
+		g.execute("gormTpl", gormTpl, struct {
+			*mapConstData
+			Size int
+		}{data, size})
+	}
+	if g.cfg.Ent && want("Values") {
+		g.execute("entTpl", entTpl, struct {
+			*mapConstData
+			Validator string
+		}{data, g.cfg.ident(typeName + "Validator")})
+	}
+	if g.cfg.TestGen && want("Generate") {
+		g.execute("testGenTpl", testGenTpl, struct {
+			*mapConstData
+			Random string
+		}{data, g.cfg.ident("Random" + typeName)})
+	}
+	if g.cfg.Navigation && want("Ordinal", "Next", "Prev") {
+		lo, hi, err := valueRange(data.Unique)
+		if err != nil {
+			return err
+		}
+		g.execute("navigationTpl", navigationTpl, struct {
+			*mapConstData
+			Lo, Hi, Min, Max string
+		}{data, lo.Name, hi.Name, g.cfg.ident(typeName + "Min"), g.cfg.ident(typeName + "Max")})
+	}
+	if g.cfg.JSON {
+		if g.cfg.JSONNumeric && basic.Info()&types.IsNumeric == 0 {
+			return errors.New("the numeric fallback of JSON requires a numeric type")
+		}
+	}
+	if g.cfg.CBOR {
+		if g.cfg.CBORNumeric && basic.Info()&types.IsNumeric == 0 {
+			return errors.New("the numeric fallback of CBOR requires a numeric type")
+		}
+		if want("MarshalCBOR", "UnmarshalCBOR") {
+			g.addImport("cbor", "github.com/fxamacker/cbor/v2")
+			g.execute("cborTpl", cborTpl, struct {
+				*mapConstData
+				Numeric bool
+			}{data, g.cfg.CBORNumeric})
+		}
+	}
+	if g.cfg.TOML && want("MarshalTOML", "UnmarshalTOML") {
+		g.execute("tomlTpl", tomlTpl, data)
+	}
+	if g.cfg.XML && want("MarshalXML", "UnmarshalXML", "MarshalXMLAttr", "UnmarshalXMLAttr") {
+		g.execute("xmlTpl", xmlTpl, data)
+	}
+	if g.cfg.Slog && want("LogValue") {
+		g.execute("slogTpl", slogTpl, data)
+	}
+	if g.cfg.JSON && want("MarshalJSON", "UnmarshalJSON") {
+		g.execute("jsonTpl", jsonTpl, struct {
+			*mapConstData
+			Numeric bool
+		}{data, g.cfg.JSONNumeric})
+	}
+	if g.cfg.ProtoGo != "" {
+		if basic.Info()&types.IsInteger == 0 {
+			return errors.New("protobuf conversions require an integer type")
+		}
+		g.addImport(g.cfg.protoGoName(), strings.SplitN(g.cfg.ProtoGo, ";", 2)[0])
+		g.execute("protoConvTpl", protoConvTpl, struct {
+			*mapConstData
+			Proto, ToProto, FromProto string
+		}{data, g.cfg.protoGoName(), g.cfg.ident(typeName + "ToProto"), g.cfg.ident(typeName + "FromProto")})
+	}
+	if g.cfg.ProtoEnum != "" {
+		if basic.Info()&types.IsInteger == 0 {
+			return errors.New("protobuf conversions require an integer type")
+		}
+		importPath, pkgName, protoType := g.cfg.protoEnum()
+		g.addImport(pkgName, importPath)
+		var consts []protoConst
+		for _, c := range data.Unique {
+			consts = append(consts, protoConst{Name: c.Name, Proto: pkgName + "." + protoType + "_" + protoName(protoType, data.Type, c)})
+		}
+		g.execute("protoEnumTpl", protoEnumTpl, struct {
+			*mapConstData
+			Proto, ToProto, FromProto string
+			ProtoConsts               []protoConst
+		}{data, pkgName + "." + protoType, g.cfg.ident(typeName + "ToProto"), g.cfg.ident(typeName + "FromProto"), consts})
+	}
+	return nil
+}
+
+// decodes reports whether any generated method decodes names, the methods
+// Lenient applies to.
+func (c *Config) decodes() bool {
+	return c.Binary == "name" || c.Msgpack || c.BSON || c.GQLGen || c.JSON || c.SQLNull || c.XML || c.TOML || c.CBOR || c.Ent || c.Gorm || c.Pgx
+}
+
+// wantMethods reports whether any method of the constant type is to be generated.
+func (c *Config) wantMethods() bool {
+	return c.Binary != "" || c.Msgpack || c.BSON || c.GQLGen || c.JSON || c.SQLNull || c.Validator || c.TestGen || c.Slog || c.XML || c.TOML || c.CBOR || c.Ent || c.Gorm || c.Pgx || c.Navigation || c.ProtoGo != "" || c.ProtoEnum != ""
+}
+
+// varName returns the identifier of the name lookup of the type, as set by
+// the VarName template.
+func (c *Config) varName(data *mapConstData) string {
+	text := c.VarName
+	if text == "" {
+		text = "{{.Type}}NameToValue"
+		if data.Lookup != "map" {
+			text = "{{.Type}}FromName"
+		}
+	}
+	tpl, err := template.New("varname").Funcs(c.funcs()).Parse(text)
+	if err != nil {
+		fatalf("parsing the name template: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		fatalf("executing the name template: %s", err)
+	}
+	name := c.ident(buf.String())
+	if !token.IsIdentifier(name) {
+		fatalf("the name template yields %q for %s, which is not an identifier", name, data.Type)
+	}
+	return name
+}
+
+// i18nKey returns the translation key of the constant of the type, as set by
+// the I18nPattern template.
+func (c *Config) i18nKey(typeName string, v Value) string {
+	text := c.I18nPattern
+	if text == "" {
+		text = "{{snake .Type}}.{{snake .Key}}"
+	}
+	tpl, err := template.New("i18n").Funcs(c.funcs()).Parse(text)
+	if err != nil {
+		fatalf("parsing the translation key template: %s", err)
+	}
+	var buf bytes.Buffer
+	data := struct{ Type, Name, Key string }{typeName, v.Name, v.Key}
+	if err := tpl.Execute(&buf, data); err != nil {
+		fatalf("executing the translation key template: %s", err)
+	}
+	return buf.String()
+}
+
+// ident returns the identifier of a generated declaration, unexported if
+// Private is set.
+func (c *Config) ident(name string) string {
+	if !c.Private || name == "" {
+		return name
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+// buildConstraint returns the //go:build line of BuildTags, surrounded by
+// blank lines, or a single blank line without it. The tags are either a
+// comma-separated list, all of which must hold, or a constraint expression.
+func (c *Config) buildConstraint() string {
+	if c.BuildTags == "" {
+		return "\n"
+	}
+	text := c.BuildTags
+	if !strings.ContainsAny(text, " &|()") {
+		text = strings.Join(strings.Split(text, ","), " && ")
+	}
+	expr, err := constraint.Parse("//go:build " + text)
+	if err != nil {
+		fatalf("invalid build tags %s: %s", c.BuildTags, err)
+	}
+	return "\n//go:build " + expr.String() + "\n\n"
+}
+
+// uniqueKeys returns the constants, failing if two of them have the same
+// key, such as Active and ACTIVE transformed to lower case, unless Aliases
+// is first, which keeps the first declared.
+func (g *Generator) uniqueKeys(consts []Value) ([]Value, error) {
+	seen := make(map[string]Value)
+	unique := consts[:0]
+	for _, c := range consts {
+		if prev, ok := seen[c.Key]; ok {
+			if g.cfg.Aliases != "first" {
+				return nil, fmt.Errorf("%s: %s has the same key %q as %s", g.pkg.fset.Position(c.pos), c.Name, c.Key, prev.Name)
+			}
+			g.cfg.logf("%s: %s skipped: same key %q as %s", g.pkg.fset.Position(c.pos), c.Name, c.Key, prev.Name)
+			continue
+		}
+		seen[c.Key] = c
+		unique = append(unique, c)
+	}
+	// Aliases come after all the keys in the lookup, so keys win.
+	for i := range unique {
+		c := &unique[i]
+		aliases := c.Aliases[:0]
+		for _, alias := range c.Aliases {
+			if prev, ok := seen[alias]; ok {
+				if g.cfg.Aliases != "first" {
+					return nil, fmt.Errorf("%s: alias %q of %s is already a key of %s", g.pkg.fset.Position(c.pos), alias, c.Name, prev.Name)
+				}
+				g.cfg.logf("%s: alias %q of %s skipped: already a key of %s", g.pkg.fset.Position(c.pos), alias, c.Name, prev.Name)
+				continue
+			}
+			seen[alias] = *c
+			aliases = append(aliases, alias)
+		}
+		c.Aliases = aliases
+	}
+	return unique, nil
+}
+
+// lookupEntries returns the entries of the name lookup of the constants:
+// the constants, then a copy of a constant keyed by each of its aliases.
+func lookupEntries(consts []Value) []Value {
+	entries := append([]Value(nil), consts...)
+	for _, c := range consts {
+		for _, alias := range c.Aliases {
+			entry := c
+			entry.Key = alias
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// uniqueValues returns the constants that have distinct values, keeping the
+// first declared of each. Constants without a resolved value are kept.
+func uniqueValues(consts []Value) []Value {
+	seen := make(map[string]bool)
+	unique := make([]Value, 0, len(consts))
+	for _, c := range consts {
+		if c.Value != nil {
+			key := c.Value.ExactString()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		unique = append(unique, c)
+	}
+	return unique
+}
+
+// valueRange returns the constants of the least and the greatest value, and
+// an error unless the values are integers covering the range between them.
+func valueRange(unique []Value) (lo, hi Value, err error) {
+	for i, c := range unique {
+		if c.Value == nil || c.Value.Kind() != constant.Int {
+			return lo, hi, fmt.Errorf("value of %s is not an integer", c.Name)
+		}
+		if i == 0 || constant.Compare(c.Value, token.LSS, lo.Value) {
+			lo = c
+		}
+		if i == 0 || constant.Compare(c.Value, token.GTR, hi.Value) {
+			hi = c
+		}
+	}
+	span := constant.BinaryOp(hi.Value, token.SUB, lo.Value)
+	if n := constant.MakeInt64(int64(len(unique) - 1)); constant.Compare(span, token.NEQ, n) {
+		return lo, hi, fmt.Errorf("values are not contiguous: %d distinct values from %s to %s", len(unique), lo.Value, hi.Value)
+	}
+	return lo, hi, nil
+}
+
+// Source returns the Go source of the generated code as a file of the
+// package named pkgName.
+func (g *Generator) Source(pkgName string) (src []byte, err error) {
+	defer catch(&err)
+	if g.cfg.Constraint && len(g.types) > 0 {
+		g.execute("constraintTpl", constraintTpl, struct {
+			Types []*mapConstData
+			Enum  string
+		}{g.types, g.cfg.ident("Enum")})
+	}
+	if g.cfg.Registry && len(g.types) > 0 {
+		g.execute("registryTpl", registryTpl, struct {
+			Types       []*mapConstData
+			Var, Lookup string
+		}{g.types, g.cfg.ident("EnumRegistry"), g.cfg.ident("LookupEnum")})
+	}
+	directive := ""
+	if g.cfg.EmitGenerate {
+		args := g.cfg.GenerateArgs
+		if args == "" {
+			args = g.cfg.Args
+		}
+		directive = "\n" + strings.TrimSpace("//go:generate mapconst "+args) + "\n"
+	}
+	return g.format(pkgName, directive), nil
+}
+
+// literal returns the constant value as it is written in wire formats: a
+// string unquoted, a number in decimal. It reports false for an unresolved
+// value.
+func literal(v constant.Value) (string, bool) {
+	if v == nil || v.Kind() == constant.Unknown {
+		return "", false
+	}
+	switch v.Kind() {
+	case constant.String:
+		return constant.StringVal(v), true
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return strconv.FormatFloat(f, 'g', -1, 64), true
+	}
+	return v.ExactString(), true
+}
+
+// format returns the generated file formatted by goimports: the header, the
+// directives following the package clause, the imports the generated code
+// needs and the contents of the Generator's buffer.
+func (g *Generator) format(pkgName, directives string) []byte {
+	var head bytes.Buffer
+	head.WriteString(g.cfg.licenseHeader("//"))
+	fmt.Fprintf(&head, headerTmpl, g.cfg.goHeader(pkgName, g.inputHash(pkgName)), pkgName, g.cfg.buildConstraint(), directives)
+
+	var buf bytes.Buffer
+	buf.Write(head.Bytes())
+	buf.WriteString(g.importDecl(append(append([]byte(nil), head.Bytes()...), g.buf.Bytes()...)))
+	buf.Write(g.buf.Bytes())
+
+	src, err := g.fixImports(buf.Bytes())
+	if err != nil {
+		// Should never happen, but can arise when developing this code.
+		// The user can compile the output to see the error.
+		g.cfg.warnf("internal error: invalid Go generated: %s", err)
+		g.cfg.warnf("compile the package to analyze the error")
+		return buf.Bytes()
+	}
+	return src
+}
+
+// skip logs, if verbose, why the constants of the spec are never generated.
+func (f *File) skip(vspec *ast.ValueSpec, reason string) {
+	if !f.cfg.verbose() {
+		return
+	}
+	for _, name := range vspec.Names {
+		if name.Name != "_" {
+			f.cfg.logf("%s: %s skipped: %s", f.pkg.fset.Position(name.Pos()), name.Name, reason)
+		}
+	}
+}
+
+// constDoc returns the text of the doc comment of the constants of the spec,
+// that of the declaration if it is the only spec, or else their line comment.
+func constDoc(decl *ast.GenDecl, vspec *ast.ValueSpec) string {
+	doc := vspec.Doc
+	if doc == nil && len(decl.Specs) == 1 {
+		doc = decl.Doc
+	}
+	if doc == nil {
+		doc = vspec.Comment
+	}
+	return strings.TrimSpace(doc.Text())
+}
+
+// constGroups returns the groups named by the //mapconst:group= directives
+// of the comments of the declaration and the spec, in order, e.g. billing
+// and payments for //mapconst:group=billing,payments.
+func constGroups(decl *ast.GenDecl, vspec *ast.ValueSpec) []string {
+	var groups []string
+	for _, doc := range []*ast.CommentGroup{decl.Doc, vspec.Doc, vspec.Comment} {
+		if doc == nil {
+			continue
+		}
+		for _, c := range doc.List {
+			if !strings.HasPrefix(c.Text, "//mapconst:group=") {
+				continue
+			}
+			for _, group := range strings.Split(strings.TrimPrefix(c.Text, "//mapconst:group="), ",") {
+				if group = strings.TrimSpace(group); group != "" {
+					groups = append(groups, group)
+				}
+			}
+		}
+	}
+	return groups
+}
+
+// constAliases returns the keys of the //mapconst:aliases=key,... directive
+// of the spec, e.g. the former spellings of a renamed constant.
+func constAliases(vspec *ast.ValueSpec) []string {
+	var aliases []string
+	for _, alias := range strings.Split(directive(vspec, "//mapconst:aliases="), ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// directive returns the argument of the directive, e.g. "//mapconst:proto=",
+// in the comments of the spec, or "" if there is none.
+func directive(vspec *ast.ValueSpec, prefix string) string {
+	for _, doc := range []*ast.CommentGroup{vspec.Doc, vspec.Comment} {
+		if doc == nil {
+			continue
+		}
+		for _, c := range doc.List {
+			if strings.HasPrefix(c.Text, prefix) {
+				return strings.TrimSpace(strings.TrimPrefix(c.Text, prefix))
+			}
+		}
+	}
+	return ""
+}
+
+// hasDirective reports whether the comments of the spec hold the directive.
+func hasDirective(vspec *ast.ValueSpec, directive string) bool {
+	for _, doc := range []*ast.CommentGroup{vspec.Doc, vspec.Comment} {
+		if doc == nil {
+			continue
+		}
+		for _, c := range doc.List {
+			if strings.TrimSpace(c.Text) == directive {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ConstDeprecation returns the message of the "Deprecated: " paragraph of
+// the doc comment of the constant declared by the spec of the declaration,
+// or "" if there is none. Such constants are left out by SkipDeprecated.
+func ConstDeprecation(decl *ast.GenDecl, vspec *ast.ValueSpec) string {
+	return deprecation(constDoc(decl, vspec))
+}
+
+// deprecation returns the message of the "Deprecated: " paragraph of the
+// doc comment, or "" if there is none.
+func deprecation(doc string) string {
+	for _, para := range strings.Split(doc, "\n\n") {
+		if strings.HasPrefix(para, "Deprecated: ") {
+			return strings.Join(strings.Fields(strings.TrimPrefix(para, "Deprecated: ")), " ")
+		}
+	}
+	return ""
+}
+
+// isGenerated reports whether mapconst generated the file.
+func isGenerated(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if strings.HasPrefix(c.Text, `// Code generated by "mapconst`) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// handWrittenMethods returns the names of the methods of the type declared
+// in files that mapconst did not generate.
+func (g *Generator) handWrittenMethods(typeName string) map[string]bool {
+	named, ok := g.pkg.lookupType(typeName).(*types.Named)
+	if !ok {
+		return nil
+	}
+	generated := make(map[string]bool)
+	for _, file := range g.pkg.files {
+		if file.generated {
+			generated[g.pkg.fset.File(file.file.Pos()).Name()] = true
+		}
+	}
+	methods := make(map[string]bool)
+	for i := 0; i < named.NumMethods(); i++ {
+		m := named.Method(i)
+		if !generated[g.pkg.fset.Position(m.Pos()).Filename] {
+			methods[m.Name()] = true
+		}
+	}
+	return methods
+}
+
+// constSpec is a spec of a const declaration with the type its constants
+// are declared with.
+type constSpec struct {
+	decl   *ast.GenDecl
+	vspec  *ast.ValueSpec
+	typ    string // The name of the type, e.g. "T" or "pkg.T".
+	reason string // Why the constants are of no type to generate, if they are not.
+}
+
+// constSpecs returns the specs of the const declarations of the file, in
+// order, including those local to functions.
+func constSpecs(file *ast.File) []constSpec {
+	var specs []constSpec
+	ast.Inspect(file, func(node ast.Node) bool {
+		decl, ok := node.(*ast.GenDecl)
+		if !ok || decl.Tok != token.CONST {
+			// We only care about const declarations.
+			return true
+		}
+		// The name of the type of the constants we are declaring.
+		// Can change if this is a multi-element declaration.
+		typ := ""
+		// Loop over the elements of the declaration. Each element is a ValueSpec:
+		// a list of names possibly followed by a type, possibly followed by values.
+		// If the type and value are both missing, we carry down the type (and value,
+		// but the "go/types" package takes care of that).
+		for _, spec := range decl.Specs {
+			vspec := spec.(*ast.ValueSpec) // Guaranteed to succeed as this is CONST.
+			if vspec.Type == nil && len(vspec.Values) > 0 {
+				// "X = 1". With no type but a value, the constant is untyped.
+				// Skip this vspec and reset the remembered type.
+				typ = ""
+				specs = append(specs, constSpec{decl: decl, vspec: vspec, reason: "untyped"})
+				continue
+			}
+			if vspec.Type != nil {
+				// "X T" or "X pkg.T". We have a type. Remember it.
+				switch t := vspec.Type.(type) {
+				case *ast.Ident:
+					typ = t.Name
+				case *ast.SelectorExpr:
+					x, ok := t.X.(*ast.Ident)
+					if !ok {
+						typ = ""
+						specs = append(specs, constSpec{decl: decl, vspec: vspec, reason: "unsupported type"})
+						continue
+					}
+					typ = x.Name + "." + t.Sel.Name
+				default:
+					typ = ""
+					specs = append(specs, constSpec{decl: decl, vspec: vspec, reason: "unsupported type"})
+					continue
+				}
+			}
+			specs = append(specs, constSpec{decl: decl, vspec: vspec, typ: typ})
+		}
+		return false
+	})
+	return specs
+}
+
+// collect adds the typed constants of the file to those of the package,
+// grouped by type: by identity if type-checked, or else by the spelling of
+// the type name.
+func (f *File) collect() {
+	for _, spec := range f.specs {
+		vspec := spec.vspec
+		if spec.reason == "untyped" {
+			// "X = A | B" is typed after all if its value is.
+			if spec.typ = f.pkg.inferredType(vspec); spec.typ != "" {
+				spec.reason = ""
+			}
+		}
+		if spec.reason != "" {
+			f.skip(vspec, spec.reason)
+			continue
+		}
+		var typ types.Type
+		if obj, ok := f.pkg.defs[vspec.Names[0]].(*types.Const); ok {
+			typ = unalias(obj.Type())
+		}
+		tc := f.pkg.typeConsts(spec.typ, typ)
+		for _, name := range vspec.Names {
+			if name.Name == "_" {
+				continue
+			}
+			v := Value{
+				Name:    name.Name,
+				Doc:     constDoc(spec.decl, vspec),
+				Groups:  constGroups(spec.decl, vspec),
+				Default: hasDirective(vspec, "//mapconst:default"),
+				Proto:   directive(vspec, "//mapconst:proto="),
+				Aliases: constAliases(vspec),
+				pos:     name.Pos(),
+			}
+			if obj, ok := f.pkg.defs[name].(*types.Const); ok {
+				v.Value = obj.Val()
+			}
+			tc.consts = append(tc.consts, v)
+		}
+	}
+}
+
+// inferredType returns the name of the named type that type-checking gave
+// the constants of the spec, declared without a type, e.g. "T" or "pkg.T",
+// or "" if they are untyped or the package did not type-check.
+func (pkg *Package) inferredType(vspec *ast.ValueSpec) string {
+	obj, ok := pkg.defs[vspec.Names[0]].(*types.Const)
+	if !ok {
+		return ""
+	}
+	named, ok := unalias(obj.Type()).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return ""
+	}
+	if named.Obj().Pkg() == obj.Pkg() {
+		return named.Obj().Name()
+	}
+	return named.Obj().Pkg().Name() + "." + named.Obj().Name()
+}
+
+// typeConsts returns the constants of the type, spelled name, adding them
+// if there are none yet.
+func (pkg *Package) typeConsts(name string, typ types.Type) *typeConsts {
+	for _, tc := range pkg.consts {
+		if typ != nil && tc.typ != nil && types.Identical(typ, tc.typ) || (typ == nil || tc.typ == nil) && name == tc.name {
+			return tc
+		}
+	}
+	tc := &typeConsts{name: name, typ: typ}
+	pkg.consts = append(pkg.consts, tc)
+	return tc
+}
+
+// constsOf returns the constants of the type named typeName in declaration
+// order: those declared with the name and, if it is type-checked, those of
+// an identical type, such as those declared with an alias. Unexported
+// constants are left out when generating into another package.
+func (g *Generator) constsOf(typeName string) []Value {
+	pkg := g.pkg
+	target := pkg.lookupType(typeName)
+	var consts []Value
+	groups := 0
+	for _, tc := range pkg.consts {
+		if tc.name != typeName && (target == nil || tc.typ == nil || !types.Identical(tc.typ, target)) {
+			continue
+		}
+		groups++
+		for _, v := range tc.consts {
+			if g.qual != "" && !token.IsExported(v.Name) {
+				g.cfg.logf("%s: %s skipped: unexported", pkg.fset.Position(v.pos), v.Name)
+				continue
+			}
+			consts = append(consts, v)
+		}
+	}
+	if groups > 1 {
+		// Files are parsed concurrently, so their positions in the file
+		// set are in no particular order; their names and offsets are.
+		sort.SliceStable(consts, func(i, j int) bool {
+			pi, pj := pkg.fset.Position(consts[i].pos), pkg.fset.Position(consts[j].pos)
+			if pi.Filename != pj.Filename {
+				return pi.Filename < pj.Filename
+			}
+			return pi.Offset < pj.Offset
+		})
+	}
+	return consts
+}
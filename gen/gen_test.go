@@ -0,0 +1,184 @@
+package gen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// statusSrc declares the types of the tests of the generated code: Status,
+// with a deprecated constant, a group, an alias and a default, and Level,
+// with negative and duplicate values, Region, of strings, and Mode, whose
+// names differ only in case.
+const statusSrc = `package p
+
+type Status int
+
+const (
+	// Active is in use.
+	//mapconst:group=live
+	Active Status = iota
+	//mapconst:group=live
+	//mapconst:aliases=running
+	Pending
+	// Deprecated: use Inactive.
+	Disabled
+	//mapconst:default
+	Inactive
+)
+
+type Level int8
+
+const (
+	Debug Level = -1
+	Info  Level = 0
+	Warn  Level = 1
+	Notice      = Warn
+)
+
+type Region string
+
+const (
+	USEast Region = "us-east-1"
+	EUWest Region = "eu-west-1"
+)
+
+type Mode int
+
+const (
+	ReadOnly Mode = iota
+	Readonly
+)
+`
+
+// TestGenerate checks that the code of each option compiles, along with
+// the package, and declares what the option is for.
+func TestGenerate(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		cfg   Config
+		types string
+		want  []string
+	}{
+		{"default", Config{}, "Status", []string{`var StatusNameToValue = map[string]Status{`, `"Active":   Active,`, `"running":  Pending,`}},
+		{"transform", Config{Transform: "snake"}, "Region", []string{`"us_east": USEast,`}},
+		{"trimprefix", Config{}, "Region:trimprefix=US", []string{`"East":   USEast,`, `"EUWest": EUWest,`}},
+		{"keyprefix", Config{KeyPrefix: "s:", KeySuffix: "!"}, "Status", []string{`"s:Active!":`}},
+		{"switch", Config{Lookup: "switch"}, "Status", []string{"func StatusFromName(s string) (Status, bool) {", `case "Active":`}},
+		{"perfecthash", Config{Lookup: "perfecthash"}, "Status", []string{"func _Status_hash(s string, seed uint32) uint32 {"}},
+		{"binarysearch", Config{Lookup: "binarysearch"}, "Status", []string{"sort.SearchStrings", `import "sort"`}},
+		{"noalloc", Config{NoAlloc: true}, "Status", []string{"func StatusFromName(s string) (Status, bool) {"}},
+		{"lazy", Config{Lazy: true}, "Status", []string{"sync.OnceValue"}},
+		{"varname", Config{VarName: "{{.Type}}ByName"}, "Status", []string{"var StatusByName = map[string]Status{"}},
+		{"private", Config{Private: true}, "Status", []string{"var statusNameToValue = map[string]Status{"}},
+		{"doc consts", Config{DocConsts: true}, "Status", []string{"// The Status constants are:"}},
+		{"namemap", Config{NameMap: true}, "Level", []string{"var LevelNames = map[Level]string{"}},
+		{"valuemap", Config{ValueMap: true}, "Region", []string{"var RegionByValue = map[string]Region{", `"us-east-1": USEast,`}},
+		{"deprecated", Config{Deprecated: true}, "Status", []string{"var StatusDeprecated = map[Status]string{", `Disabled: "use Inactive."`}},
+		{"groups", Config{Groups: true}, "Status", []string{"StatusInLive"}},
+		{"constraint", Config{Constraint: true}, "Status,Level", []string{"type StatusLike interface", "type Enum interface"}},
+		{"registry", Config{Registry: true}, "Status,Level", []string{"var EnumRegistry = map[string]map[string]interface{}"}},
+		{"pairs", Config{Pairs: true}, "Status", []string{"var StatusPairs = "}},
+		{"infos", Config{Infos: true}, "Status", []string{"type StatusInfo struct", `Doc: "Active is in use."`}},
+		{"exhaustive", Config{Exhaustive: true}, "Status", []string{"//exhaustive:enforce"}},
+		{"assert", Config{Assert: true}, "Level", []string{"_ = x[Debug-(-1)]"}},
+		{"count", Config{Count: true}, "Level", []string{"const LevelCount = 3"}},
+		{"contiguous", Config{Contiguous: true}, "Status", []string{"const StatusCount = 4"}},
+		{"navigation", Config{Navigation: true}, "Level", []string{"LevelMin = Debug ", "func (v Level) Next() (Level, bool) {"}},
+		{"i18n", Config{I18n: true}, "Status", []string{`Active:   "status.active",`}},
+		{"mustparse", Config{MustParse: true}, "Status", []string{"func MustParseStatus(s string) Status {"}},
+		{"env", Config{Env: true}, "Status", []string{"func StatusFromEnv(key string, def Status) (Status, error) {"}},
+		{"http", Config{HTTP: true}, "Status", []string{"func StatusFromQuery(r *http.Request, key string) (Status, error) {"}},
+		{"skip deprecated", Config{SkipDeprecated: true}, "Status", nil},
+		{"aliases first", Config{Aliases: "first", Transform: "lower"}, "Mode", []string{`"readonly": ReadOnly,`}},
+		{"buildtags", Config{BuildTags: "linux,!cgo"}, "Status", []string{"//go:build linux && !cgo"}},
+		{"header", Config{Header: "Copyright 2026 The Authors."}, "Status", []string{"// Copyright 2026 The Authors.\n\n// Code generated by"}},
+		{"emit generate", Config{EmitGenerate: true, Args: "-type=Status"}, "Status", []string{"//go:generate mapconst -type=Status"}},
+		{"append", Config{Append: true}, "Status", []string{"// mapconst:begin Status\n", "// mapconst:end Status\n"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			out := source(t, load(t, statusSrc, &cfg, tt.types))
+			wantContains(t, out, tt.want...)
+			typeCheck(t, statusSrc, out)
+		})
+	}
+}
+
+// TestGenerateSkips checks which constants the options leave out of the
+// name map.
+func TestGenerateSkips(t *testing.T) {
+	out := source(t, load(t, statusSrc, &Config{SkipDeprecated: true}, "Status"))
+	if strings.Contains(out, `"Disabled"`) {
+		t.Errorf("-skip-deprecated kept Disabled:\n%s", out)
+	}
+	out = source(t, load(t, statusSrc, &Config{Aliases: "first", Transform: "lower"}, "Mode"))
+	if strings.Contains(out, "Readonly") {
+		t.Errorf("aliases=first kept the second of the same key:\n%s", out)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		cfg     Config
+		types   string
+		wantErr string
+	}{
+		{"no constants", Config{}, "Missing", ErrNoConsts.Error()},
+		{"duplicate keys", Config{Transform: "lower"}, "Mode", `same key "readonly"`},
+		{"lenient without default", Config{Lenient: true, JSON: true}, "Level", "no constant is marked //mapconst:default"},
+		{"navigation of strings", Config{Navigation: true}, "Region", "value of USEast is not an integer"},
+		{"contiguous from 0", Config{Contiguous: true}, "Level", "values are not contiguous from 0"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			g, err := LoadSource("src.go", []byte(statusSrc), &cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = g.Generate(TypeSpec{Name: tt.types, Transform: cfg.Transform})
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if tt.name == "no constants" && !errors.Is(err, ErrNoConsts) {
+				t.Errorf("error %v is not ErrNoConsts", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		cfg     Config
+		wantErr string
+	}{
+		{Config{}, ""},
+		{Config{Binary: "hex"}, "invalid binary encoding"},
+		{Config{Lookup: "trie"}, "invalid lookup"},
+		{Config{Aliases: "last"}, "invalid aliases"},
+		{Config{Normalize: "nfd"}, "invalid normalization"},
+		{Config{TemplateFuncs: map[string]map[string]string{"a-b": nil}}, "invalid template function name"},
+		{Config{JSONNumeric: true}, "requires JSON"},
+		{Config{CBORNumeric: true}, "requires CBOR"},
+		{Config{Lenient: true}, "Lenient requires methods decoding names"},
+		{Config{Lenient: true, Slog: true, Binary: "value"}, "Lenient requires methods decoding names"},
+		{Config{Lenient: true, Binary: "name"}, ""},
+		{Config{Lenient: true, Gorm: true}, ""},
+		{Config{NameMap: true, NoAlloc: true}, "cannot be combined with NoAlloc"},
+		{Config{Lazy: true, Lookup: "switch"}, "lazy maps require the map lookup"},
+		{Config{Append: true, Registry: true}, "Append cannot be combined"},
+		{Config{ProtoEnum: "example.com/pb.Status", ProtoGo: "example.com/pb"}, "cannot be combined with those of ProtoGo"},
+	} {
+		err := tt.cfg.validate()
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%+v: %v", tt.cfg, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%+v: error %v, want one containing %q", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
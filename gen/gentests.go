@@ -0,0 +1,231 @@
+package gen
+
+type testsData struct {
+	*mapConstData
+	Lookups bool // Whether the lookups of lookupTpl were generated.
+	Binary  bool
+	Msgpack bool
+	BSON    bool
+	GQLGen  bool
+	JSON    bool
+	Tests   bool // Whether to generate the tests.
+	Fuzz    bool // Whether to generate the fuzz target.
+	Bench   bool // Whether to generate the benchmarks.
+}
+
+var testsTpl string = `{{if or .Tests .Fuzz .Bench}}
+var _{{.Type}}_testConsts = []struct {
+	name  string
+	value {{.TypeQual}}{{.Type}}
+}{
+	{{range .Consts}} { {{printf "%q" .Key}}, {{$.Qual}}{{.Name}}},
+	{{end}}
+}
+{{end}}
+{{- if .Tests}}
+func Test{{.Type}}FromName(t *testing.T) {
+	{{- if eq .Lookup "map"}}
+	if len({{.NameMap}}) != {{len .Entries}} {
+		t.Errorf("{{.Var}} has %d entries, want {{len .Entries}}", len({{.NameMap}}))
+	}
+	{{- end}}
+	for _, tt := range _{{.Type}}_testConsts {
+		if v, ok := {{.FromName "tt.name"}}; !ok || v != tt.value {
+			t.Errorf("lookup of %q = %v, %t; want %v", tt.name, v, ok, tt.value)
+		}
+	}
+	if v, ok := {{.FromName (printf "%q" (printf "not a %s" .Type))}}; ok {
+		t.Errorf("lookup of an unknown name = %v, want none", v)
+	}
+}
+{{if .Lookups}}
+func Test{{.Type}}Lookups(t *testing.T) {
+	for _, tt := range _{{.Type}}_testConsts {
+		s, ok := _{{.Type}}_toName(tt.value)
+		if !ok {
+			t.Errorf("no name for the value of %s", tt.name)
+			continue
+		}
+		if v, ok := _{{.Type}}_fromName(s); !ok || v != tt.value {
+			t.Errorf("%s: name %q does not round-trip", tt.name, s)
+		}
+	}
+}
+{{if or .Binary .Msgpack .BSON .GQLGen .JSON}}
+func Test{{.Type}}RoundTrip(t *testing.T) {
+	for _, tt := range _{{.Type}}_testConsts {
+		name, value := tt.name, tt.value
+		{{- if .Binary}}
+		if b, err := value.MarshalBinary(); err != nil {
+			t.Errorf("%s: MarshalBinary: %v", name, err)
+		} else {
+			var v {{.Type}}
+			if err := v.UnmarshalBinary(b); err != nil || v != value {
+				t.Errorf("%s: UnmarshalBinary(%q) = %v, %v", name, b, v, err)
+			}
+		}
+		{{- end}}
+		{{- if .Msgpack}}
+		if b, err := msgpack.Marshal(value); err != nil {
+			t.Errorf("%s: msgpack.Marshal: %v", name, err)
+		} else {
+			var v {{.Type}}
+			if err := msgpack.Unmarshal(b, &v); err != nil || v != value {
+				t.Errorf("%s: msgpack.Unmarshal(%q) = %v, %v", name, b, v, err)
+			}
+		}
+		{{- end}}
+		{{- if .BSON}}
+		if typ, b, err := value.MarshalBSONValue(); err != nil {
+			t.Errorf("%s: MarshalBSONValue: %v", name, err)
+		} else {
+			var v {{.Type}}
+			if err := v.UnmarshalBSONValue(typ, b); err != nil || v != value {
+				t.Errorf("%s: UnmarshalBSONValue(%q) = %v, %v", name, b, v, err)
+			}
+		}
+		{{- end}}
+		{{- if .GQLGen}}
+		{
+			var buf bytes.Buffer
+			value.MarshalGQL(&buf)
+			s, err := strconv.Unquote(buf.String())
+			var v {{.Type}}
+			if err != nil {
+				t.Errorf("%s: MarshalGQL wrote %s: %v", name, buf.String(), err)
+			} else if err := v.UnmarshalGQL(s); err != nil || v != value {
+				t.Errorf("%s: UnmarshalGQL(%q) = %v, %v", name, s, v, err)
+			}
+		}
+		{{- end}}
+		{{- if .JSON}}
+		if b, err := json.Marshal(value); err != nil {
+			t.Errorf("%s: json.Marshal: %v", name, err)
+		} else {
+			var v {{.Type}}
+			if err := json.Unmarshal(b, &v); err != nil || v != value {
+				t.Errorf("%s: json.Unmarshal(%s) = %v, %v", name, b, v, err)
+			}
+		}
+		{{- end}}
+	}
+}
+{{end}}{{end}}{{end}}
+{{- if .Fuzz}}
+func FuzzParse{{.Type}}(f *testing.F) {
+	for _, tt := range _{{.Type}}_testConsts {
+		f.Add(tt.name)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		{{- if .Lookups}}
+		if v, ok := _{{.Type}}_fromName(s); ok {
+			name, ok := _{{.Type}}_toName(v)
+			if !ok {
+				t.Fatalf("%q parsed to %v, which has no name", s, v)
+			}
+			if w, ok := _{{.Type}}_fromName(name); !ok || w != v {
+				t.Fatalf("%q parsed to %v, whose name %q parses to %v", s, v, name, w)
+			}
+		}
+		{{- if .Binary}}
+		var v {{.Type}}
+		if err := v.UnmarshalBinary([]byte(s)); err == nil {
+			b, err := v.MarshalBinary()
+			if err != nil {
+				t.Fatalf("%q unmarshaled to %v, which does not marshal: %v", s, v, err)
+			}
+			var w {{.Type}}
+			if err := w.UnmarshalBinary(b); err != nil || w != v {
+				t.Fatalf("%q unmarshaled to %v, which does not round-trip: %v, %v", s, v, w, err)
+			}
+		}
+		{{- end}}
+		{{- if .GQLGen}}
+		var g {{.Type}}
+		if err := g.UnmarshalGQL(s); err == nil {
+			if _, ok := _{{.Type}}_toName(g); !ok {
+				t.Fatalf("%q unmarshaled to %v, which has no name", s, g)
+			}
+		}
+		{{- end}}
+		{{- else}}
+		v, ok := {{.FromName "s"}}
+		for _, tt := range _{{.Type}}_testConsts {
+			if tt.name == s && (!ok || tt.value != v) {
+				t.Fatalf("%q parsed to %v, %t; want %v", s, v, ok, tt.value)
+			}
+		}
+		{{- end}}
+	})
+}
+{{end}}
+{{- if .Bench}}
+var bench{{.Type}}Inputs = []string{
+	{{range .Consts}} {{printf "%q" .Key}},
+	{{end}} "not a {{.Type}}",
+}
+
+var bench{{.Type}}Sink {{.TypeQual}}{{.Type}}
+
+func bench{{.Type}}Switch(s string) ({{.TypeQual}}{{.Type}}, bool) {
+	switch s {
+	{{- range .Consts}}
+	case {{printf "%q" .Key}}:
+		return {{$.Qual}}{{.Name}}, true
+	{{- end}}
+	}
+	var zero {{.TypeQual}}{{.Type}}
+	return zero, false
+}
+
+func bench{{.Type}}Parse(s string) ({{.TypeQual}}{{.Type}}, error) {
+	v, ok := {{.FromName "s"}}
+	if !ok {
+		return v, fmt.Errorf("invalid {{.Type}} name %q", s)
+	}
+	return v, nil
+}
+
+func Benchmark{{.Type}}Lookup(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		bench{{.Type}}Sink, _ = {{.FromName (printf "bench%sInputs[i%%len(bench%sInputs)]" .Type .Type)}}
+	}
+}
+
+func Benchmark{{.Type}}Switch(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		bench{{.Type}}Sink, _ = bench{{.Type}}Switch(bench{{.Type}}Inputs[i%len(bench{{.Type}}Inputs)])
+	}
+}
+
+func Benchmark{{.Type}}Parse(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		bench{{.Type}}Sink, _ = bench{{.Type}}Parse(bench{{.Type}}Inputs[i%len(bench{{.Type}}Inputs)])
+	}
+}
+{{end}}`
+
+// Tests returns a test file of the output package, named pkgName, with
+// table-driven tests of the generated declarations, fuzz targets of the
+// generated parsing and benchmarks of the ways to look names up, as set by
+// the Tests, Fuzz and Benchmarks options.
+func (g *Generator) Tests(pkgName string) (src []byte, err error) {
+	defer catch(&err)
+	tg := &Generator{cfg: g.cfg, pkg: g.pkg, qual: g.qual, imports: g.imports, types: g.types}
+	lookups := g.qual == "" && g.cfg.wantMethods()
+	for _, data := range g.types {
+		tg.execute("testsTpl", testsTpl, &testsData{
+			mapConstData: data,
+			Lookups:      lookups,
+			Binary:       g.cfg.Binary != "",
+			Msgpack:      g.cfg.Msgpack,
+			BSON:         g.cfg.BSON,
+			GQLGen:       g.cfg.GQLGen,
+			JSON:         g.cfg.JSON,
+			Tests:        g.cfg.Tests,
+			Fuzz:         g.cfg.Fuzz,
+			Bench:        g.cfg.Benchmarks,
+		})
+	}
+	return tg.format(pkgName, ""), nil
+}
@@ -0,0 +1,83 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// ConstTypeAfter returns the type of the first const declaration after the
+// line of the file, as named by the first of its specs with a type. Run by
+// go generate, which sets $GOFILE and $GOLINE, mapconst generates for the
+// declaration following its directive, e.g. Pill for
+//
+//	//go:generate mapconst
+//	const (
+//		Placebo Pill = iota
+//		Aspirin
+//	)
+func ConstTypeAfter(filename string, line int) (string, error) {
+	fs := token.NewFileSet()
+	file, err := parser.ParseFile(fs, filename, nil, 0)
+	if err != nil {
+		return "", err
+	}
+	for _, decl := range file.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.CONST || fs.Position(decl.Pos()).Line <= line {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			if vspec := spec.(*ast.ValueSpec); vspec.Type != nil {
+				return types.ExprString(vspec.Type), nil
+			}
+		}
+		return "", fmt.Errorf("%s: the const declaration after line %d has no type", filename, line)
+	}
+	return "", fmt.Errorf("%s: no const declaration after line %d", filename, line)
+}
+
+// TypesDirective returns the types to generate as set by the
+// //mapconst:types= directives among the comments of the files of the
+// loaded package, in the syntax of ParseTypeSpecs. A directive lists types
+// and may set -trimprefix, -transform, -keyprefix and -keysuffix for them,
+// e.g.
+//
+//	//mapconst:types=Pill,Status transform=snake
+//
+// which yields "Pill:transform=snake;Status:transform=snake".
+func (g *Generator) TypesDirective() (string, error) {
+	var entries []string
+	for _, f := range g.pkg.files {
+		if f.generated {
+			continue
+		}
+		for _, group := range f.file.Comments {
+			for _, c := range group.List {
+				if !strings.HasPrefix(c.Text, "//mapconst:types=") {
+					continue
+				}
+				fields := strings.Fields(strings.TrimPrefix(c.Text, "//mapconst:types="))
+				if len(fields) == 0 {
+					return "", fmt.Errorf("%s: no types in %s", g.pkg.fset.Position(c.Pos()), c.Text)
+				}
+				for _, option := range fields[1:] {
+					kv := strings.SplitN(option, "=", 2)
+					if len(kv) != 2 || kv[0] != "trimprefix" && kv[0] != "transform" && kv[0] != "keyprefix" && kv[0] != "keysuffix" {
+						return "", fmt.Errorf("%s: invalid option %q; must be trimprefix=prefix, transform=name, keyprefix=prefix or keysuffix=suffix", g.pkg.fset.Position(c.Pos()), option)
+					}
+				}
+				for _, name := range strings.Split(fields[0], ",") {
+					if len(fields) > 1 {
+						name += ":" + strings.Join(fields[1:], ",")
+					}
+					entries = append(entries, name)
+				}
+			}
+		}
+	}
+	return strings.Join(entries, ";"), nil
+}
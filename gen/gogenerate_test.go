@@ -0,0 +1,90 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConstTypeAfter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "src.go")
+	src := `package p
+
+//go:generate mapconst
+const (
+	Active Status = iota
+	Inactive
+)
+
+//go:generate mapconst
+const (
+	big   = 1 << 20
+	Debug Level = -1
+)
+
+//go:generate mapconst
+const untyped = 1
+
+//go:generate mapconst
+var x = 0
+`
+	if err := os.WriteFile(filename, []byte(src), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		line    int
+		want    string
+		wantErr string
+	}{
+		{3, "Status", ""},
+		{9, "Level", ""},
+		{1, "Status", ""},
+		{15, "", "the const declaration after line 15 has no type"},
+		{18, "", "no const declaration after line 18"},
+	} {
+		got, err := ConstTypeAfter(filename, tt.line)
+		switch {
+		case tt.wantErr != "":
+			if err == nil || err.Error() != filename+": "+tt.wantErr {
+				t.Errorf("line %d: error %v, want %q", tt.line, err, tt.wantErr)
+			}
+		case err != nil:
+			t.Errorf("line %d: %v", tt.line, err)
+		case got != tt.want:
+			t.Errorf("line %d: type %s, want %s", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestTypesDirective(t *testing.T) {
+	for _, tt := range []struct {
+		directives string
+		want       string
+		wantErr    string
+	}{
+		{"", "", ""},
+		{"//mapconst:types=Status", "Status", ""},
+		{"//mapconst:types=Status,Level transform=snake trimprefix=S", "Status:transform=snake,trimprefix=S;Level:transform=snake,trimprefix=S", ""},
+		{"//mapconst:types=Status\n//mapconst:types=Level keysuffix=!", "Status;Level:keysuffix=!", ""},
+		{"//mapconst:types=", "", "no types in //mapconst:types="},
+		{"//mapconst:types=Status lookup=switch", "", `invalid option "lookup=switch"`},
+	} {
+		src := "package p\n\n" + tt.directives + "\n\ntype Status int\n\ntype Level int\n"
+		g, err := LoadSource("src.go", []byte(src), new(Config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := g.TypesDirective()
+		switch {
+		case tt.wantErr != "":
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q: error %v, want one containing %q", tt.directives, err, tt.wantErr)
+			}
+		case err != nil:
+			t.Errorf("%q: %v", tt.directives, err)
+		case got != tt.want:
+			t.Errorf("%q: types %q, want %q", tt.directives, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// graphqlName matches the names GraphQL allows for enum values.
+var graphqlName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// GraphQL returns a GraphQL schema declaring an enum per generated type,
+// valued by the keys of its name map.
+func (g *Generator) GraphQL() (src []byte, err error) {
+	defer catch(&err)
+	var buf bytes.Buffer
+	buf.WriteString(g.cfg.licenseHeader("#"))
+	buf.WriteString(g.cfg.generatedBy("#"))
+	for _, data := range g.types {
+		fmt.Fprintf(&buf, "\nenum %s {\n", data.Type)
+		for _, c := range data.Consts {
+			if !graphqlName.MatchString(c.Key) {
+				fatalf("%s is not a valid GraphQL enum value", c.Key)
+			}
+			fmt.Fprintf(&buf, "  %s\n", c.Key)
+		}
+		buf.WriteString("}\n")
+	}
+	return buf.Bytes(), nil
+}
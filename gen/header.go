@@ -0,0 +1,154 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// generatedBy returns the comment lines, in the syntax of the line comment
+// marker, that mark a file as generated by mapconst with the Args and record
+// the version of mapconst.
+func (c *Config) generatedBy(marker string) string {
+	return c.generatedLine(marker) + fmt.Sprintf("%s mapconst version: %s\n", marker, Version())
+}
+
+// generatedLine returns the "Code generated" line of generatedBy, recording
+// the command line.
+func (c *Config) generatedLine(marker string) string {
+	command := "mapconst"
+	if c.Args != "" {
+		command += " " + c.Args
+	}
+	return fmt.Sprintf("%s Code generated by \"%s\"; DO NOT EDIT.\n", marker, command)
+}
+
+// Version returns the version of mapconst and, if known, the commit it was
+// built from.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	v := info.Main.Version
+	if v == "" {
+		v = "(devel)"
+	}
+	var revision, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		}
+	}
+	if revision != "" {
+		if len(revision) > 12 {
+			revision = revision[:12]
+		}
+		v += " " + revision
+		if modified == "true" {
+			v += "-dirty"
+		}
+	}
+	return v
+}
+
+// licenseHeader returns the Header notice as a comment in the syntax of the
+// line comment marker, followed by a blank line, or "" without one. The
+// notice may be plain text or commented with //, # or --; its markers are
+// replaced, so one notice serves outputs in every language.
+func (c *Config) licenseHeader(marker string) string {
+	if c.Header == "" {
+		return ""
+	}
+	text := strings.TrimRight(strings.Replace(c.Header, "\r\n", "\n", -1), "\n")
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		for _, m := range []string{"//", "#", "--"} {
+			if strings.HasPrefix(line, m) {
+				line = strings.TrimPrefix(strings.TrimPrefix(line, m), " ")
+				break
+			}
+		}
+		b.WriteString(marker)
+		if line != "" {
+			b.WriteString(" " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// headerData is the data of HeaderTemplate.
+type headerData struct {
+	Package   string // Name of the package of the file.
+	Command   string // The mapconst command line: "mapconst" followed by Args.
+	Args      string // Arguments of the command line.
+	Version   string // Version of mapconst.
+	Timestamp string // Time of generation in RFC 3339 if Timestamp is set, or "".
+	Hash      string // The input hash line; see InputHashPrefix.
+}
+
+// InputHashPrefix starts the line of the header of Go output recording a
+// hash of the inputs of the generation: the options, the version of
+// mapconst, the constants with all the fields the templates read and the
+// methods written by hand. Output with the same hash as an existing file
+// need not be written again.
+const InputHashPrefix = "// mapconst input hash: "
+
+// inputHash returns the hash of the inputs of the Go output of the package
+// named pkgName, in hexadecimal.
+func (g *Generator) inputHash(pkgName string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s\n%q\n%q\n%v\n", Version(), g.cfg.Args, g.cfg.GenerateArgs, pkgName, g.cfg.Header, g.cfg.HeaderTemplate, g.cfg.TemplateFuncs)
+	h.Write([]byte(g.merged))
+	for _, data := range g.types {
+		fmt.Fprintf(h, "%s%s %s %s %q\n", data.TypeQual, data.Type, data.Qual, data.Underlying, data.skipped)
+		for _, c := range data.Consts {
+			value := "?"
+			if c.Value != nil {
+				value = c.Value.ExactString()
+			}
+			fmt.Fprintf(h, "%s %q %s %q %q %q %t %q %q\n", c.Name, c.Key, value, c.Lit, c.Doc, c.Groups, c.Default, c.Proto, c.Aliases)
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)[:16])
+}
+
+// goHeader returns the comment lines preceding the package clause of Go
+// output: those of HeaderTemplate if set, or else the lines of generatedBy.
+func (c *Config) goHeader(pkgName, hash string) string {
+	hashLine := InputHashPrefix + hash + "\n"
+	if c.HeaderTemplate == "" {
+		return c.generatedBy("//") + hashLine
+	}
+	tpl, err := template.New("header").Funcs(c.funcs()).Parse(c.HeaderTemplate)
+	if err != nil {
+		fatalf("parsing the header template: %s", err)
+	}
+	data := headerData{
+		Package: pkgName,
+		Command: strings.TrimSpace("mapconst " + c.Args),
+		Args:    c.Args,
+		Version: Version(),
+		Hash:    strings.TrimSuffix(hashLine, "\n"),
+	}
+	if c.Timestamp {
+		data.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	var b strings.Builder
+	if err := tpl.Execute(&b, data); err != nil {
+		fatalf("executing the header template: %s", err)
+	}
+	text := b.String()
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text
+}
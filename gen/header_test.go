@@ -0,0 +1,77 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLicenseHeader(t *testing.T) {
+	for _, tt := range []struct {
+		header, marker string
+		want           string
+	}{
+		{"", "//", ""},
+		{"Copyright 2026 The Authors.", "//", "// Copyright 2026 The Authors.\n\n"},
+		{"Copyright 2026 The Authors.", "#", "# Copyright 2026 The Authors.\n\n"},
+		{"// Copyright.\n//\n// Licensed under MIT.\n", "--", "-- Copyright.\n--\n-- Licensed under MIT.\n\n"},
+		{"# Copyright.\r\n#Licensed under MIT.", "//", "// Copyright.\n// Licensed under MIT.\n\n"},
+		{"-- Copyright.\n\nLicensed under MIT.", "#", "# Copyright.\n#\n# Licensed under MIT.\n\n"},
+	} {
+		c := &Config{Header: tt.header}
+		if got := c.licenseHeader(tt.marker); got != tt.want {
+			t.Errorf("licenseHeader of %q with %s = %q, want %q", tt.header, tt.marker, got, tt.want)
+		}
+	}
+}
+
+func TestGoHeader(t *testing.T) {
+	for _, tt := range []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{}, "// Code generated by \"mapconst\"; DO NOT EDIT.\n"},
+		{Config{Args: "-type=Status"}, "// Code generated by \"mapconst -type=Status\"; DO NOT EDIT.\n"},
+		{Config{Args: "-type=Status", HeaderTemplate: "// {{.Command}} for {{.Package}}; DO NOT EDIT.\n{{.Hash}}"}, "// mapconst -type=Status for p; DO NOT EDIT.\n" + InputHashPrefix + "0123\n"},
+		{Config{HeaderTemplate: "// {{upper .Package}}"}, "// P\n"},
+	} {
+		got := tt.cfg.goHeader("p", "0123")
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("goHeader with %+v = %q, want it to start with %q", tt.cfg, got, tt.want)
+		}
+		if tt.cfg.HeaderTemplate == "" && !strings.HasSuffix(got, InputHashPrefix+"0123\n") {
+			t.Errorf("goHeader with %+v = %q lacks the input hash", tt.cfg, got)
+		}
+	}
+}
+
+// TestInputHash checks that the input hash changes with each input of the
+// output, and only with those.
+func TestInputHash(t *testing.T) {
+	hash := func(src string, cfg Config) string {
+		g := load(t, src, &cfg, "Status")
+		return g.inputHash("p")
+	}
+	base := hash(statusSrc, Config{})
+	if got := hash(statusSrc, Config{}); got != base {
+		t.Errorf("hash of the same inputs changed from %s to %s", base, got)
+	}
+	unrelated := strings.Replace(statusSrc, "type Region string", "// Region is a cloud region.\ntype Region string", 1)
+	if got := hash(unrelated, Config{}); got != base {
+		t.Errorf("hash changed with a comment of another type")
+	}
+	for name, tt := range map[string]struct {
+		src string
+		cfg Config
+	}{
+		"doc":      {strings.Replace(statusSrc, "Active is in use.", "Active is live.", 1), Config{}},
+		"value":    {strings.Replace(statusSrc, "Active Status = iota", "Active Status = iota + 1", 1), Config{}},
+		"group":    {strings.Replace(statusSrc, "group=live\n\tActive", "group=up\n\tActive", 1), Config{}},
+		"args":     {statusSrc, Config{Args: "-type=Status"}},
+		"header":   {statusSrc, Config{Header: "Copyright."}},
+		"template": {statusSrc, Config{HeaderTemplate: "// {{.Command}}"}},
+	} {
+		if got := hash(tt.src, tt.cfg); got == base {
+			t.Errorf("hash did not change with the %s", name)
+		}
+	}
+}
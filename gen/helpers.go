@@ -0,0 +1,82 @@
+package gen
+
+// Templates of helper functions parsing constant names from the outside
+// world. Unlike methods, they can be generated for types of other packages.
+
+var envTpl string = `
+// {{.FromEnv}} returns the {{.Type}} constant named by the environment variable
+// key, ignoring case and surrounding space, or def if the variable is unset or
+// empty.
+func {{.FromEnv}}(key string, def {{.TypeQual}}{{.Type}}) ({{.TypeQual}}{{.Type}}, error) {
+	s := strings.TrimSpace(os.Getenv(key))
+	if s == "" {
+		return def, nil
+	}
+	if v, ok := {{.FromName "s"}}; ok {
+		return v, nil
+	}
+	for _, c := range [...]struct {
+		name  string
+		value {{.TypeQual}}{{.Type}}
+	}{
+		{{range .Consts}} { {{printf "%q" .Key}}, {{$.Qual}}{{.Name}}},
+		{{end}}
+	} {
+		if strings.EqualFold(c.name, s) {
+			return c.value, nil
+		}
+	}
+	return def, fmt.Errorf("%s: invalid {{.Type}} name %q", key, s)
+}
+`
+
+var httpTpl string = `
+// {{.Error}} reports a request parameter that does not name a {{.Type}}
+// constant, e.g. for a 400 response.
+type {{.Error}} struct {
+	Param   string   // The name of the parameter.
+	Value   string   // The value of the parameter; empty if it is missing.
+	Allowed []string // The names of the {{.Type}} constants.
+}
+
+// Error implements error.
+func (e *{{.Error}}) Error() string {
+	return fmt.Sprintf("invalid {{.Type}} %q in %s; must be one of %s", e.Value, e.Param, strings.Join(e.Allowed, ", "))
+}
+
+// {{.FromQuery}} returns the {{.Type}} constant named by the query parameter key
+// of r, or an *{{.Error}}.
+func {{.FromQuery}}(r *http.Request, key string) ({{.TypeQual}}{{.Type}}, error) {
+	return _{{.Type}}_fromParam(key, r.URL.Query().Get(key))
+}
+
+// {{.FromPath}} returns the {{.Type}} constant named by the wildcard key of the
+// path pattern matching r, or an *{{.Error}}. It requires Go 1.22.
+func {{.FromPath}}(r *http.Request, key string) ({{.TypeQual}}{{.Type}}, error) {
+	return _{{.Type}}_fromParam(key, r.PathValue(key))
+}
+
+func _{{.Type}}_fromParam(key, s string) ({{.TypeQual}}{{.Type}}, error) {
+	if v, ok := {{.FromName "s"}}; ok {
+		return v, nil
+	}
+	var zero {{.TypeQual}}{{.Type}}
+	return zero, &{{.Error}}{
+		Param:   key,
+		Value:   s,
+		Allowed: []string{ {{range .Consts}}{{printf "%q" .Key}}, {{end}} },
+	}
+}
+`
+
+var mustParseTpl string = `
+// {{.MustParse}} returns the {{.Type}} constant named s. It panics if there is
+// none, e.g. for names in tests and configuration read at init time.
+func {{.MustParse}}(s string) {{.TypeQual}}{{.Type}} {
+	v, ok := {{.FromName "s"}}
+	if !ok {
+		panic(fmt.Sprintf("invalid {{.Type}} name %q; must be one of %s", s, {{printf "%q" .Valid}}))
+	}
+	return v
+}
+`
@@ -0,0 +1,92 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// load returns the Generator of the package of the source, a file named
+// src.go, having generated the types.
+func load(t *testing.T, src string, cfg *Config, types ...string) *Generator {
+	t.Helper()
+	if cfg == nil {
+		cfg = new(Config)
+	}
+	g, err := LoadSource("src.go", []byte(src), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range types {
+		specs, err := ParseTypeSpecs(typ, cfg.TrimPrefix, cfg.Transform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, spec := range specs {
+			if err := g.Generate(spec); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return g
+}
+
+// source returns the Go output of the Generator, failing the test on error.
+func source(t *testing.T, g *Generator) string {
+	t.Helper()
+	src, err := g.Source(g.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(src)
+}
+
+// fset and stdImporter type-check the packages of the standard library
+// imported by the tests once.
+var (
+	fset        = token.NewFileSet()
+	stdImporter = importer.ForCompiler(fset, "source", nil)
+)
+
+// typeCheck type-checks the package of the files, named by their sources,
+// and fails the test if it does not compile.
+func typeCheck(t *testing.T, srcs ...string) {
+	t.Helper()
+	var files []*ast.File
+	for i, src := range srcs {
+		file, err := parser.ParseFile(fset, fmt.Sprintf("file%d.go", i), src, 0)
+		if err != nil {
+			t.Fatalf("%s\n%s", err, numbered(src))
+		}
+		files = append(files, file)
+	}
+	conf := types.Config{Importer: stdImporter}
+	if _, err := conf.Check(files[0].Name.Name, fset, files, nil); err != nil {
+		t.Fatalf("%s\n%s", err, numbered(srcs[len(srcs)-1]))
+	}
+}
+
+// numbered returns the source with line numbers, for failure messages.
+func numbered(src string) string {
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		lines[i] = fmt.Sprintf("%4d  %s", i+1, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wantContains fails the test unless the output contains each of the
+// strings.
+func wantContains(t *testing.T, out string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("output lacks %q:\n%s", w, numbered(out))
+		}
+	}
+}
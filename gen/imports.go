@@ -0,0 +1,131 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/imports"
+)
+
+// stdImports maps the names of the packages of the standard library that
+// templates refer to to their import paths. They are spelled out rather than
+// left to goimports, which may pick another package of the same name, such
+// as math/rand/v2 for rand.
+var stdImports = map[string]string{
+	"binary":  "encoding/binary",
+	"bytes":   "bytes",
+	"context": "context",
+	"driver":  "database/sql/driver",
+	"errors":  "errors",
+	"fmt":     "fmt",
+	"http":    "net/http",
+	"io":      "io",
+	"json":    "encoding/json",
+	"os":      "os",
+	"rand":    "math/rand",
+	"reflect": "reflect",
+	"slog":    "log/slog",
+	"sort":    "sort",
+	"sql":     "database/sql",
+	"strconv": "strconv",
+	"strings": "strings",
+	"sync":    "sync",
+	"testing": "testing",
+	"xml":     "encoding/xml",
+}
+
+// addImport records that the generated code may refer to the package of
+// the import path by name. It is needed for the packages not in stdImports,
+// and those the code ends up not referring to are left out.
+func (g *Generator) addImport(name, importPath string) {
+	if g.imports == nil {
+		g.imports = make(map[string]string)
+	}
+	g.imports[name] = importPath
+}
+
+// importDecl returns the import declaration that the Go source of a file
+// without imports needs: one import for each package name it refers to,
+// resolved through the imports added to the generator, then stdImports.
+// It returns "" if src cannot be parsed.
+func (g *Generator) importDecl(src []byte) string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return ""
+	}
+	// Selectors on identifiers not declared in the file refer to packages.
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil {
+				used[x.Name] = true
+			}
+		}
+		return true
+	})
+
+	// Standard library imports come first, as goimports groups them.
+	var std, other []string
+	for name := range used {
+		importPath, ok := g.imports[name]
+		if !ok {
+			importPath, ok = stdImports[name]
+		}
+		if !ok {
+			g.cfg.warnf("internal error: no import path for package %s", name)
+			continue
+		}
+		spec := fmt.Sprintf("%q", importPath)
+		if importName(importPath) != name {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	if len(std)+len(other) == 0 {
+		return ""
+	}
+	byPath := func(specs []string) {
+		sort.Slice(specs, func(i, j int) bool {
+			return specs[i][strings.Index(specs[i], `"`):] < specs[j][strings.Index(specs[j], `"`):]
+		})
+	}
+	byPath(std)
+	byPath(other)
+	specs := std
+	if len(std) > 0 && len(other) > 0 {
+		specs = append(specs, "")
+	}
+	specs = append(specs, other...)
+	if len(specs) == 1 {
+		return "\nimport " + specs[0] + "\n"
+	}
+	return "\nimport (\n\t" + strings.Join(specs, "\n\t") + "\n)\n"
+}
+
+// fixImports returns the Go source formatted by goimports as if it were a
+// file of the loaded package. The imports are those of importDecl: goimports
+// neither adds nor removes any.
+func (g *Generator) fixImports(src []byte) ([]byte, error) {
+	filename := filepath.Join(g.pkg.dir, "mapconst_generated.go")
+	return imports.Process(filename, src, &imports.Options{Comments: true, TabIndent: true, TabWidth: 8, FormatOnly: true})
+}
+
+// importName returns the package name implied by an import path: its last
+// element, skipping a major version suffix such as /v5.
+func importName(importPath string) string {
+	base := path.Base(importPath)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		return path.Base(path.Dir(importPath))
+	}
+	return base
+}
@@ -0,0 +1,31 @@
+package gen
+
+import (
+	"testing"
+)
+
+func TestImportDecl(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		imports map[string]string
+		src     string
+		want    string
+	}{
+		{"none", nil, "package p\n\nvar x = 1\n", ""},
+		{"std", nil, "package p\n\nvar x = fmt.Sprint(1)\n", "\nimport \"fmt\"\n"},
+		{"math/rand", nil, "package p\n\nfunc f(r *rand.Rand) int { return r.Intn(2) }\n", "\nimport \"math/rand\"\n"},
+		{"grouped", map[string]string{"msgpack": "github.com/vmihailenco/msgpack/v5"},
+			"package p\n\nvar _ msgpack.Marshaler\nvar _ = strings.ToLower\nvar _ sync.Once\n",
+			"\nimport (\n\t\"strings\"\n\t\"sync\"\n\t\n\t\"github.com/vmihailenco/msgpack/v5\"\n)\n"},
+		{"renamed", map[string]string{"pb": "example.com/api/v1"}, "package p\n\nvar _ pb.Status\n", "\nimport pb \"example.com/api/v1\"\n"},
+		{"unused", map[string]string{"pb": "example.com/api/v1"}, "package p\n\nvar x = 1\n", ""},
+		{"declared", nil, "package p\n\nvar fmt struct{ X int }\nvar x = fmt.X\n", ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Generator{cfg: new(Config), imports: tt.imports}
+			if got := g.importDecl([]byte(tt.src)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
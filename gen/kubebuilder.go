@@ -0,0 +1,38 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kubebuilder returns a +kubebuilder:validation:Enum marker per generated
+// type listing the keys of its name map, for the doc comments of the type or
+// of CRD fields holding it, so that controller-gen restricts them to the
+// names the generated code decodes.
+func (g *Generator) Kubebuilder() (src []byte, err error) {
+	defer catch(&err)
+	var buf bytes.Buffer
+	buf.WriteString(g.cfg.licenseHeader("//"))
+	buf.WriteString(g.cfg.generatedBy("//"))
+	for _, data := range g.types {
+		keys := make([]string, len(data.Consts))
+		for i, c := range data.Consts {
+			keys[i] = markerValue(c.Key)
+		}
+		fmt.Fprintf(&buf, "\n// %s\n// +kubebuilder:validation:Enum=%s\n", data.Type, strings.Join(keys, ";"))
+	}
+	return buf.Bytes(), nil
+}
+
+// markerValue returns s as a value of a controller-gen marker list, quoted
+// unless it consists of letters, digits, dashes, dots and underscores only.
+func markerValue(s string) string {
+	if s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '_')
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,192 @@
+package gen
+
+import (
+	"sort"
+)
+
+// Templates of the name lookups selected by Config.Lookup. Each but the map
+// declares a function, <type>FromName by default, which returns the constant
+// named s and whether there is one.
+
+var switchLookupTpl string = `
+// {{.Var}} returns the {{.Type}} constant named s and whether there is one.
+{{- template "constList" .}}
+func {{.Var}}(s string) ({{.TypeQual}}{{.Type}}, bool) {
+	switch s {
+	{{- range .Entries}}
+	case {{printf "%q" .Key}}:
+		return {{$.Qual}}{{.Name}}, true
+	{{- end}}
+	}
+	var zero {{.TypeQual}}{{.Type}}
+	return zero, false
+}
+`
+
+var perfectHashLookupTpl string = `
+// {{.Var}} returns the {{.Type}} constant named s and whether there is one.
+{{- template "constList" .}}
+func {{.Var}}(s string) ({{.TypeQual}}{{.Type}}, bool) {
+	n := uint32(len(_{{.Type}}_table))
+	e := &_{{.Type}}_table[_{{.Type}}_hash(s, _{{.Type}}_seeds[_{{.Type}}_hash(s, 0)%n])%n]
+	if e.name == s {
+		return e.value, true
+	}
+	var zero {{.TypeQual}}{{.Type}}
+	return zero, false
+}
+
+// _{{.Type}}_seeds holds the seed of the second hash of {{.Var}}, indexed by
+// the first hash.
+var _{{.Type}}_seeds = [...]uint32{ {{range .Hash.Seeds}}{{.}}, {{end}} }
+
+// _{{.Type}}_table holds the {{.Type}} constants, indexed by the second hash of
+// their names.
+var _{{.Type}}_table = [...]struct {
+	name  string
+	value {{.TypeQual}}{{.Type}}
+}{
+	{{range .Hash.Table}} { {{printf "%q" .Key}}, {{$.Qual}}{{.Name}}},
+	{{end}}
+}
+
+// _{{.Type}}_hash is 32-bit FNV-1a with the offset basis perturbed by seed,
+// finalized by the murmur3 mixer so that all bits depend on the seed.
+func _{{.Type}}_hash(s string, seed uint32) uint32 {
+	h := 2166136261 ^ seed
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+`
+
+var binarySearchLookupTpl string = `
+// {{.Var}} returns the {{.Type}} constant named s and whether there is one.
+{{- template "constList" .}}
+func {{.Var}}(s string) ({{.TypeQual}}{{.Type}}, bool) {
+	i := sort.SearchStrings(_{{.Type}}_sortedNames[:], s)
+	if i < len(_{{.Type}}_sortedNames) && _{{.Type}}_sortedNames[i] == s {
+		return _{{.Type}}_sortedValues[i], true
+	}
+	var zero {{.TypeQual}}{{.Type}}
+	return zero, false
+}
+
+// _{{.Type}}_sortedNames holds the names of the {{.Type}} constants, sorted for
+// binary search.
+var _{{.Type}}_sortedNames = [...]string{
+	{{range .Sorted}} {{printf "%q" .Key}},
+	{{end}}
+}
+
+// _{{.Type}}_sortedValues holds the {{.Type}} constants in the order of
+// _{{.Type}}_sortedNames.
+var _{{.Type}}_sortedValues = [...]{{.TypeQual}}{{.Type}}{
+	{{range .Sorted}} {{$.Qual}}{{.Name}},
+	{{end}}
+}
+`
+
+// FromName returns the Go expression looking up the constant named by the
+// expression arg. Like a map index, it yields the constant and whether it
+// exists when assigned to two values.
+func (d *mapConstData) FromName(arg string) string {
+	if d.Lookup == "map" {
+		return d.NameMap() + "[" + arg + "]"
+	}
+	return d.Var + "(" + arg + ")"
+}
+
+// NameMap returns the Go expression of the map of names to constants.
+func (d *mapConstData) NameMap() string {
+	if d.Lazy {
+		return d.Var + "()"
+	}
+	return d.Var
+}
+
+// perfectHash is a minimal perfect hash of constant names.
+type perfectHash struct {
+	Seeds []uint32 // Second-level seed, indexed by first-level hash.
+	Table []Value  // The constants, indexed by second-level hash.
+}
+
+// fnv32 must match the _<type>_hash function of perfectHashLookupTpl.
+func fnv32(s string, seed uint32) uint32 {
+	h := 2166136261 ^ seed
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// newPerfectHash builds a minimal perfect hash of the constant names by hash
+// and displace: names are bucketed by hashing with seed 0, then, largest
+// bucket first, each bucket is given the first seed that hashes all its names
+// into free slots.
+func newPerfectHash(consts []Value) *perfectHash {
+	n := uint32(len(consts))
+	buckets := make([][]int, n)
+	for i, c := range consts {
+		b := fnv32(c.Key, 0) % n
+		buckets[b] = append(buckets[b], i)
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(buckets[order[i]]) > len(buckets[order[j]])
+	})
+
+	h := &perfectHash{
+		Seeds: make([]uint32, n),
+		Table: make([]Value, n),
+	}
+	used := make([]bool, n)
+	for _, b := range order {
+		bucket := buckets[b]
+		if len(bucket) == 0 {
+			break
+		}
+		slots := make([]uint32, len(bucket))
+	Seed:
+		for seed := uint32(1); ; seed++ {
+			if seed == 1<<24 {
+				fatalf("cannot build a perfect hash of %d names", n)
+			}
+			for i, c := range bucket {
+				slot := fnv32(consts[c].Key, seed) % n
+				if used[slot] {
+					continue Seed
+				}
+				for _, prev := range slots[:i] {
+					if prev == slot {
+						continue Seed
+					}
+				}
+				slots[i] = slot
+			}
+			h.Seeds[b] = seed
+			for i, c := range bucket {
+				used[slots[i]] = true
+				h.Table[slots[i]] = consts[c]
+			}
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,93 @@
+package gen
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// lookup looks the key up in the perfect hash as the generated function of
+// perfectHashLookupTpl does, reporting whether it is found.
+func (h *perfectHash) lookup(key string) bool {
+	n := uint32(len(h.Table))
+	if n == 0 {
+		return false
+	}
+	return h.Table[fnv32(key, h.Seeds[fnv32(key, 0)%n])%n].Key == key
+}
+
+// randomKeys returns n distinct random keys of up to 12 bytes.
+func randomKeys(r *rand.Rand, n int) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for len(keys) < n {
+		b := make([]byte, 1+r.Intn(12))
+		for i := range b {
+			b[i] = byte(' ' + r.Intn(95))
+		}
+		if key := string(b); !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// collidingKeys returns n distinct keys of the same first-level hash bucket
+// of a table of n.
+func collidingKeys(n int) []string {
+	var keys []string
+	for i := 0; len(keys) < n; i++ {
+		key := fmt.Sprint("k", i)
+		if fnv32(key, 0)%uint32(n) == 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func TestPerfectHash(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	sets := map[string][]string{
+		"empty":     nil,
+		"single":    {"only"},
+		"empty key": {"", "a"},
+		"colliding": collidingKeys(8),
+	}
+	for _, n := range []int{2, 3, 10, 100, 1000} {
+		for i := 0; i < 5; i++ {
+			sets[fmt.Sprintf("random %d #%d", n, i)] = randomKeys(r, n)
+		}
+	}
+	for name, keys := range sets {
+		t.Run(name, func(t *testing.T) {
+			consts := make([]Value, len(keys))
+			for i, key := range keys {
+				consts[i] = Value{Name: fmt.Sprint("C", i), Key: key}
+			}
+			h := newPerfectHash(consts)
+			if len(h.Table) != len(keys) || len(h.Seeds) != len(keys) {
+				t.Fatalf("%d keys: table of %d, %d seeds", len(keys), len(h.Table), len(h.Seeds))
+			}
+			for _, key := range keys {
+				if !h.lookup(key) {
+					t.Errorf("key %q not found", key)
+				}
+			}
+			for _, key := range randomKeys(r, 20) {
+				if h.lookup(key) != contains(keys, key) {
+					t.Errorf("key %q: found %t", key, !contains(keys, key))
+				}
+			}
+		})
+	}
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/constant"
+	"strconv"
+	"strings"
+)
+
+// Markdown returns a Markdown document with a table per generated type
+// listing each constant with its value, the key of its name map and its doc
+// comment.
+func (g *Generator) Markdown() (src []byte, err error) {
+	defer catch(&err)
+	var buf bytes.Buffer
+	buf.WriteString("<!--\n")
+	buf.WriteString(g.cfg.licenseHeader(""))
+	buf.WriteString(g.cfg.generatedBy(""))
+	buf.WriteString("-->\n")
+	for _, data := range g.types {
+		fmt.Fprintf(&buf, "\n## %s\n\n", data.Type)
+		buf.WriteString("| Constant | Value | Name | Description |\n")
+		buf.WriteString("| --- | --- | --- | --- |\n")
+		for _, c := range data.Consts {
+			value := "?"
+			if lit, ok := literal(c.Value); ok {
+				value = lit
+				if c.Value.Kind() == constant.String {
+					value = strconv.Quote(lit)
+				}
+			}
+			fmt.Fprintf(&buf, "| `%s` | `%s` | `%s` | %s |\n", c.Name, mdEscape(value), mdEscape(c.Key), mdEscape(c.Doc))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// mdEscape returns s fit for a cell of a Markdown table: on one line, with
+// pipes escaped.
+func mdEscape(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.Replace(s, "|", `\|`, -1)
+}
@@ -0,0 +1,555 @@
+package gen
+
+// Templates of the methods generated on the constant type. They rely on the
+// lookups of lookupTpl.
+
+var binaryNameTpl string = `
+// MarshalBinary implements encoding.BinaryMarshaler by encoding the name of the constant.
+func (v {{.Type}}) MarshalBinary() ([]byte, error) {
+	s, ok := _{{.Type}}_toName(v)
+	if !ok {
+		return nil, fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(v))
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler by decoding the name of the constant.
+func (v *{{.Type}}) UnmarshalBinary(data []byte) error {
+	x, ok := _{{.Type}}_decode(string(data))
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", data)
+	}
+	*v = x
+	return nil
+}
+`
+
+var binaryValueTpl string = `
+// MarshalBinary implements encoding.BinaryMarshaler by encoding the value of the constant as a varint.
+func (v {{.Type}}) MarshalBinary() ([]byte, error) {
+	if _, ok := _{{.Type}}_toName(v); !ok {
+		return nil, fmt.Errorf("invalid {{.Type}} value %d", {{.Underlying}}(v))
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	{{if .Unsigned}}n := binary.PutUvarint(buf, uint64(v)){{else}}n := binary.PutVarint(buf, int64(v)){{end}}
+	return buf[:n], nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler by decoding the value of the constant from a varint.
+func (v *{{.Type}}) UnmarshalBinary(data []byte) error {
+	{{if .Unsigned}}x, n := binary.Uvarint(data){{else}}x, n := binary.Varint(data){{end}}
+	if n <= 0 || n != len(data) {
+		return fmt.Errorf("invalid {{.Type}} encoding %x", data)
+	}
+	if {{if .Unsigned}}uint64{{else}}int64{{end}}({{.Type}}(x)) != x {
+		return fmt.Errorf("invalid {{.Type}} value %d", x)
+	}
+	if _, ok := _{{.Type}}_toName({{.Type}}(x)); !ok {
+		return fmt.Errorf("invalid {{.Type}} value %d", x)
+	}
+	*v = {{.Type}}(x)
+	return nil
+}
+`
+
+var msgpackTpl string = `
+// EncodeMsgpack implements msgpack.CustomEncoder by encoding the name of the constant.
+func (v {{.Type}}) EncodeMsgpack(enc *msgpack.Encoder) error {
+	s, ok := _{{.Type}}_toName(v)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(v))
+	}
+	return enc.EncodeString(s)
+}
+
+// DecodeMsgpack implements msgpack.CustomDecoder by decoding the name of the constant.
+func (v *{{.Type}}) DecodeMsgpack(dec *msgpack.Decoder) error {
+	s, err := dec.DecodeString()
+	if err != nil {
+		return err
+	}
+	x, ok := _{{.Type}}_decode(s)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", s)
+	}
+	*v = x
+	return nil
+}
+`
+
+var bsonTpl string = `
+// MarshalBSONValue implements bson.ValueMarshaler by encoding the name of the constant as a BSON string.
+func (v {{.Type}}) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	s, ok := _{{.Type}}_toName(v)
+	if !ok {
+		return 0, nil, fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(v))
+	}
+	return bsontype.String, bsoncore.AppendString(nil, s), nil
+}
+
+// UnmarshalBSONValue implements bson.ValueUnmarshaler by decoding the name of the constant from a BSON string.
+func (v *{{.Type}}) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t != bsontype.String {
+		return fmt.Errorf("cannot decode BSON %s into {{.Type}}", t)
+	}
+	s, _, ok := bsoncore.ReadString(data)
+	if !ok {
+		return fmt.Errorf("invalid BSON string for {{.Type}}")
+	}
+	x, ok := _{{.Type}}_decode(s)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", s)
+	}
+	*v = x
+	return nil
+}
+`
+
+var gqlgenTpl string = `
+// MarshalGQL implements graphql.Marshaler by writing the name of the constant.
+func (v {{.Type}}) MarshalGQL(w io.Writer) {
+	s, _ := _{{.Type}}_toName(v)
+	io.WriteString(w, strconv.Quote(s))
+}
+
+// UnmarshalGQL implements graphql.Unmarshaler by reading the name of the constant.
+func (v *{{.Type}}) UnmarshalGQL(i interface{}) error {
+	s, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("{{.Type}} must be a string, got %T", i)
+	}
+	x, ok := _{{.Type}}_decode(s)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", s)
+	}
+	*v = x
+	return nil
+}
+`
+
+var jsonTpl string = `
+// MarshalJSON implements json.Marshaler by encoding the name of the constant as a JSON string.
+func (v {{.Type}}) MarshalJSON() ([]byte, error) {
+	s, ok := _{{.Type}}_toName(v)
+	if !ok {
+		return nil, fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(v))
+	}
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler by decoding the name of the constant from a JSON string
+{{- if .Numeric}}, or its value from a JSON number{{end}}.
+func (v *{{.Type}}) UnmarshalJSON(data []byte) error {
+	{{- if .Numeric}}
+	if len(data) > 0 && data[0] != '"' {
+		var x {{.Underlying}}
+		if err := json.Unmarshal(data, &x); err != nil {
+			return fmt.Errorf("{{.Type}} must be a string or a number: %w", err)
+		}
+		if _, ok := _{{.Type}}_toName({{.Type}}(x)); !ok {
+			return fmt.Errorf("invalid {{.Type}} value %v", x)
+		}
+		*v = {{.Type}}(x)
+		return nil
+	}
+	{{- end}}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("{{.Type}} must be a string: %w", err)
+	}
+	x, ok := _{{.Type}}_decode(s)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", s)
+	}
+	*v = x
+	return nil
+}
+`
+
+var sqlNullTpl string = `
+// Null{{.Type}} represents a {{.Type}} that may be null, stored as the name of
+// the constant. It implements sql.Scanner and driver.Valuer like
+// sql.NullString.
+type Null{{.Type}} struct {
+	{{.Type}} {{.Type}}
+	Valid bool // Valid is true if {{.Type}} is not NULL.
+}
+
+// Scan implements sql.Scanner by decoding the name of the constant.
+func (n *Null{{.Type}}) Scan(value interface{}) error {
+	var s string
+	switch value := value.(type) {
+	case nil:
+		*n = Null{{.Type}}{}
+		return nil
+	case string:
+		s = value
+	case []byte:
+		s = string(value)
+	default:
+		return fmt.Errorf("cannot scan %T into Null{{.Type}}", value)
+	}
+	x, ok := _{{.Type}}_decode(s)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", s)
+	}
+	n.{{.Type}}, n.Valid = x, true
+	return nil
+}
+
+// Value implements driver.Valuer by encoding the name of the constant.
+func (n Null{{.Type}}) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	s, ok := _{{.Type}}_toName(n.{{.Type}})
+	if !ok {
+		return nil, fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(n.{{.Type}}))
+	}
+	return s, nil
+}
+`
+
+var sqlValueTpl string = `
+// Scan implements sql.Scanner by decoding the name of the constant.
+func (v *{{.Type}}) Scan(value interface{}) error {
+	var s string
+	switch value := value.(type) {
+	case string:
+		s = value
+	case []byte:
+		s = string(value)
+	default:
+		return fmt.Errorf("cannot scan %T into {{.Type}}", value)
+	}
+	x, ok := _{{.Type}}_decode(s)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", s)
+	}
+	*v = x
+	return nil
+}
+
+// Value implements driver.Valuer by encoding the name of the constant.
+func (v {{.Type}}) Value() (driver.Value, error) {
+	s, ok := _{{.Type}}_toName(v)
+	if !ok {
+		return nil, fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(v))
+	}
+	return s, nil
+}
+`
+
+var entTpl string = `
+// Values implements the EnumValues interface of entgo.io, listing the names of
+// the constants as the values of enum fields of type {{.Type}}.
+func ({{.Type}}) Values() []string {
+	return []string{
+		{{range .Consts}} {{printf "%q" .Key}},
+		{{end}}
+	}
+}
+
+// {{.Validator}} reports whether v is a {{.Type}} constant, as the validator
+// of ent enum fields, e.g. field.Enum("status").GoType({{.Type}}(0)).Validate({{.Validator}}).
+func {{.Validator}}(v {{.Type}}) error {
+	if _, ok := _{{.Type}}_toName(v); !ok {
+		return fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(v))
+	}
+	return nil
+}
+`
+
+var pgxTpl string = `
+// ScanText implements pgtype.TextScanner by decoding the name of the constant.
+func (v *{{.Type}}) ScanText(t pgtype.Text) error {
+	if !t.Valid {
+		return fmt.Errorf("cannot scan NULL into {{.Type}}")
+	}
+	x, ok := _{{.Type}}_decode(t.String)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", t.String)
+	}
+	*v = x
+	return nil
+}
+
+// TextValue implements pgtype.TextValuer by encoding the name of the constant.
+func (v {{.Type}}) TextValue() (pgtype.Text, error) {
+	s, ok := _{{.Type}}_toName(v)
+	if !ok {
+		return pgtype.Text{}, fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(v))
+	}
+	return pgtype.Text{String: s, Valid: true}, nil
+}
+
+// {{.Register}} loads the Postgres enum type {{.PgType}}, as declared by the SQL
+// DDL of mapconst, into the type map of conn and makes it the type of
+// {{.Type}} parameters.
+func {{.Register}}(ctx context.Context, conn *pgx.Conn) error {
+	t, err := conn.LoadType(ctx, {{printf "%q" .PgType}})
+	if err != nil {
+		return err
+	}
+	var v {{.Type}}
+	conn.TypeMap().RegisterType(t)
+	conn.TypeMap().RegisterDefaultPgType(v, {{printf "%q" .PgType}})
+	return nil
+}
+`
+
+var gormTpl string = `
+// GormDataType implements schema.GormDataTypeInterface; {{.Type}} is stored as
+// the name of the constant.
+func ({{.Type}}) GormDataType() string {
+	return "string"
+}
+
+// GormDBDataType implements migrator.GormDBDataTypeInterface with a column
+// type that fits the longest name of the {{.Type}} constants.
+func ({{.Type}}) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "sqlite":
+		return "text"
+	case "sqlserver":
+		return "nvarchar({{.Size}})"
+	}
+	return "varchar({{.Size}})"
+}
+`
+
+var validatorTpl string = `
+// {{.OneOfName}} holds the names of the {{.Type}} constants as the parameter of
+// the oneof tag of github.com/go-playground/validator, for validating string
+// fields by a tag in sync with the constants.
+const {{.OneOfName}} = {{printf "%q" .OneOf}}
+
+// {{.Register}} registers the {{.Tag}} validation, which accepts
+// {{.Type}} fields holding a constant and string fields holding the name of one.
+func {{.Register}}(v *validator.Validate) error {
+	return v.RegisterValidation({{printf "%q" .Tag}}, func(fl validator.FieldLevel) bool {
+		switch x := fl.Field().Interface().(type) {
+		case {{.Type}}:
+			_, ok := _{{.Type}}_toName(x)
+			return ok
+		case string:
+			_, ok := _{{.Type}}_fromName(x)
+			return ok
+		}
+		return false
+	})
+}
+`
+
+var testGenTpl string = `
+// _{{.Type}}_values holds the distinct {{.Type}} values.
+var _{{.Type}}_values = [...]{{.Type}}{
+	{{range .Unique}} {{.Name}},
+	{{end}}
+}
+
+// {{.Random}} returns a {{.Type}} constant drawn uniformly by r, for
+// property-based tests.
+func {{.Random}}(r *rand.Rand) {{.Type}} {
+	return _{{.Type}}_values[r.Intn(len(_{{.Type}}_values))]
+}
+
+// Generate implements quick.Generator by drawing a {{.Type}} constant.
+func ({{.Type}}) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf({{.Random}}(r))
+}
+`
+
+var navigationTpl string = `
+const (
+	{{.Min}} = {{.Lo}} // {{.Min}} is the {{.Type}} constant of the least value.
+	{{.Max}} = {{.Hi}} // {{.Max}} is the {{.Type}} constant of the greatest value.
+)
+
+// Ordinal returns the position of v among the {{.Type}} values, from 0 for
+// {{.Min}}, or -1 if v is not a {{.Type}} constant.
+func (v {{.Type}}) Ordinal() int {
+	if v < {{.Min}} || v > {{.Max}} {
+		return -1
+	}
+	return int(v - {{.Min}})
+}
+
+// Next returns the {{.Type}} constant following v and whether there is one.
+func (v {{.Type}}) Next() ({{.Type}}, bool) {
+	if v < {{.Min}} || v >= {{.Max}} {
+		return v, false
+	}
+	return v + 1, true
+}
+
+// Prev returns the {{.Type}} constant preceding v and whether there is one.
+func (v {{.Type}}) Prev() ({{.Type}}, bool) {
+	if v <= {{.Min}} || v > {{.Max}} {
+		return v, false
+	}
+	return v - 1, true
+}
+`
+
+var cborTpl string = `
+// MarshalCBOR implements cbor.Marshaler by encoding the name of the constant as a CBOR text string.
+func (v {{.Type}}) MarshalCBOR() ([]byte, error) {
+	s, ok := _{{.Type}}_toName(v)
+	if !ok {
+		return nil, fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(v))
+	}
+	return cbor.Marshal(s)
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler by decoding the name of the constant from a CBOR text string
+{{- if .Numeric}}, or its value from a CBOR number{{end}}.
+func (v *{{.Type}}) UnmarshalCBOR(data []byte) error {
+	{{- if .Numeric}}
+	if len(data) > 0 && data[0]>>5 != 3 {
+		var x {{.Underlying}}
+		if err := cbor.Unmarshal(data, &x); err != nil {
+			return fmt.Errorf("{{.Type}} must be a text string or a number: %w", err)
+		}
+		if _, ok := _{{.Type}}_toName({{.Type}}(x)); !ok {
+			return fmt.Errorf("invalid {{.Type}} value %v", x)
+		}
+		*v = {{.Type}}(x)
+		return nil
+	}
+	{{- end}}
+	var s string
+	if err := cbor.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("{{.Type}} must be a text string: %w", err)
+	}
+	x, ok := _{{.Type}}_decode(s)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", s)
+	}
+	*v = x
+	return nil
+}
+`
+
+var tomlTpl string = `
+// MarshalTOML implements the Marshaler of go-toml by encoding the name of the constant as a TOML string.
+func (v {{.Type}}) MarshalTOML() ([]byte, error) {
+	s, ok := _{{.Type}}_toName(v)
+	if !ok {
+		return nil, fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(v))
+	}
+	return []byte(strconv.Quote(s)), nil
+}
+
+// UnmarshalTOML implements the Unmarshaler of BurntSushi/toml by decoding the name of the constant from a TOML string.
+func (v *{{.Type}}) UnmarshalTOML(data interface{}) error {
+	s, ok := data.(string)
+	if !ok {
+		return fmt.Errorf("{{.Type}} must be a string, not %T", data)
+	}
+	x, ok := _{{.Type}}_decode(s)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", s)
+	}
+	*v = x
+	return nil
+}
+`
+
+var xmlTpl string = `
+// MarshalXML implements xml.Marshaler by encoding the name of the constant as the element text.
+func (v {{.Type}}) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	s, ok := _{{.Type}}_toName(v)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(v))
+	}
+	return e.EncodeElement(s, start)
+}
+
+// UnmarshalXML implements xml.Unmarshaler by decoding the name of the constant from the element text.
+func (v *{{.Type}}) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	x, ok := _{{.Type}}_decode(s)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", s)
+	}
+	*v = x
+	return nil
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr by encoding the name of the constant as the attribute value.
+func (v {{.Type}}) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	s, ok := _{{.Type}}_toName(v)
+	if !ok {
+		return xml.Attr{}, fmt.Errorf("invalid {{.Type}} value %v", {{.Underlying}}(v))
+	}
+	return xml.Attr{Name: name, Value: s}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr by decoding the name of the constant from the attribute value.
+func (v *{{.Type}}) UnmarshalXMLAttr(attr xml.Attr) error {
+	x, ok := _{{.Type}}_decode(attr.Value)
+	if !ok {
+		return fmt.Errorf("invalid {{.Type}} name %q", attr.Value)
+	}
+	*v = x
+	return nil
+}
+`
+
+var slogTpl string = `
+// LogValue implements slog.LogValuer by logging the name of the constant, or
+// else the value.
+func (v {{.Type}}) LogValue() slog.Value {
+	if s, ok := _{{.Type}}_toName(v); ok {
+		return slog.StringValue(s)
+	}
+	return slog.AnyValue({{.Underlying}}(v))
+}
+`
+
+var protoEnumTpl string = `
+// {{.ToProto}} converts v to the value of {{.Proto}} of the same name. It
+// returns the zero value for values that {{.Type}} does not declare.
+func {{.ToProto}}(v {{.Type}}) {{.Proto}} {
+	switch v {
+	{{- range .ProtoConsts}}
+	case {{.Name}}:
+		return {{.Proto}}
+	{{- end}}
+	}
+	return 0
+}
+
+// {{.FromProto}} converts the value of {{.Proto}} to the {{.Type}} of the same
+// name. It fails for values without one, such as the unspecified value.
+func {{.FromProto}}(p {{.Proto}}) ({{.Type}}, error) {
+	switch p {
+	{{- range .ProtoConsts}}
+	case {{.Proto}}:
+		return {{.Name}}, nil
+	{{- end}}
+	}
+	return 0, fmt.Errorf("invalid {{.Type}} protobuf value %d", int32(p))
+}
+`
+
+var protoConvTpl string = `
+// {{.ToProto}} converts v to the protobuf enum mirroring {{.Type}}.
+func {{.ToProto}}(v {{.Type}}) {{.Proto}}.{{.Type}} {
+	return {{.Proto}}.{{.Type}}(v)
+}
+
+// {{.FromProto}} converts the protobuf enum mirroring {{.Type}} back. It fails
+// for values that {{.Type}} does not declare, such as the unspecified value.
+func {{.FromProto}}(p {{.Proto}}.{{.Type}}) ({{.Type}}, error) {
+	v := {{.Type}}(p)
+	if _, ok := _{{.Type}}_toName(v); !ok {
+		return 0, fmt.Errorf("invalid {{.Type}} value %d", int32(p))
+	}
+	return v, nil
+}
+`
@@ -0,0 +1,101 @@
+package gen
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+// TestMethods checks that the methods of each option using the standard
+// library only compile and are declared.
+func TestMethods(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		cfg   Config
+		types string
+		want  []string
+	}{
+		{"json", Config{JSON: true}, "Status", []string{"func (v Status) MarshalJSON() ([]byte, error) {", "func (v *Status) UnmarshalJSON(data []byte) error {"}},
+		{"json numeric", Config{JSON: true, JSONNumeric: true}, "Level", []string{"func (v *Level) UnmarshalJSON(data []byte) error {"}},
+		{"json lenient", Config{JSON: true, Lenient: true}, "Status", []string{"Inactive"}},
+		{"binary name", Config{Binary: "name"}, "Status", []string{"func (v Status) MarshalBinary() ([]byte, error) {"}},
+		{"binary value", Config{Binary: "value"}, "Level", []string{"func (v *Level) UnmarshalBinary(data []byte) error {"}},
+		{"sqlnull", Config{SQLNull: true}, "Status", []string{"type NullStatus struct", "func (n NullStatus) Value() (driver.Value, error) {"}},
+		{"xml", Config{XML: true}, "Region", []string{"func (v Region) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {"}},
+		{"slog", Config{Slog: true}, "Status", []string{"func (v Status) LogValue() slog.Value {"}},
+		{"testgen", Config{TestGen: true}, "Status", []string{"func RandomStatus(r *rand.Rand) Status {", "func (Status) Generate(r *rand.Rand, size int) reflect.Value {"}},
+		{"navigation", Config{Navigation: true}, "Status", []string{"func (v Status) Ordinal() int {"}},
+		{"all", Config{JSON: true, Binary: "name", SQLNull: true, XML: true, Slog: true, TestGen: true, Navigation: true, Env: true, HTTP: true, MustParse: true}, "Status,Level", nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			out := source(t, load(t, statusSrc, &cfg, tt.types))
+			wantContains(t, out, tt.want...)
+			typeCheck(t, statusSrc, out)
+		})
+	}
+}
+
+// TestMethodsThirdParty checks that the methods of each option using a
+// package outside the standard library parse and import it.
+func TestMethodsThirdParty(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		cfg  Config
+		want []string
+	}{
+		{"msgpack", Config{Msgpack: true}, []string{`"github.com/vmihailenco/msgpack/v5"`, "DecodeMsgpack(dec *msgpack.Decoder) error"}},
+		{"bson", Config{BSON: true}, []string{"MarshalBSONValue() (bsontype.Type, []byte, error)"}},
+		{"gqlgen", Config{GQLGen: true}, []string{"MarshalGQL(w io.Writer)"}},
+		{"validator", Config{Validator: true}, []string{`"github.com/go-playground/validator/v10"`, "func RegisterStatusValidation(v *validator.Validate) error {"}},
+		{"pgx", Config{Pgx: true}, []string{"ScanText(t pgtype.Text) error"}},
+		{"gorm", Config{Gorm: true}, []string{"GormDBDataType(db *gorm.DB, field *schema.Field) string"}},
+		{"ent", Config{Ent: true}, []string{"func (Status) Values() []string {"}},
+		{"cbor", Config{CBOR: true, CBORNumeric: true}, []string{`"github.com/fxamacker/cbor/v2"`, "UnmarshalCBOR(data []byte) error"}},
+		{"toml", Config{TOML: true}, []string{"MarshalTOML() ([]byte, error)"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			out := source(t, load(t, statusSrc, &cfg, "Status"))
+			wantContains(t, out, tt.want...)
+			if _, err := parser.ParseFile(token.NewFileSet(), "out.go", out, 0); err != nil {
+				t.Errorf("%v:\n%s", err, numbered(out))
+			}
+		})
+	}
+}
+
+// TestMethodsDeclared checks that the methods of an option are skipped
+// together once one is declared by hand, or fail the type with Strict.
+func TestMethodsDeclared(t *testing.T) {
+	src := statusSrc + `
+func (v Status) MarshalJSON() ([]byte, error) { return nil, nil }
+`
+	out := source(t, load(t, src, &Config{JSON: true}, "Status"))
+	if strings.Contains(out, "MarshalJSON") {
+		t.Errorf("the JSON methods were generated along with MarshalJSON declared by hand:\n%s", out)
+	}
+	typeCheck(t, src, out)
+
+	g, err := LoadSource("src.go", []byte(src), &Config{JSON: true, Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(TypeSpec{Name: "Status"}); err == nil || !strings.Contains(err.Error(), "MarshalJSON") {
+		t.Errorf("Strict: error %v, want one naming MarshalJSON", err)
+	}
+}
+
+// TestTests checks that the Tests output compiles along with the package
+// and the code it tests.
+func TestTests(t *testing.T) {
+	cfg := Config{JSON: true, Binary: "name", Tests: true, Fuzz: true, Benchmarks: true}
+	g := load(t, statusSrc, &cfg, "Status", "Level")
+	tests, err := g.Tests(g.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantContains(t, string(tests), "func TestStatusFromName(t *testing.T) {", "func FuzzParseStatus(f *testing.F) {", "func BenchmarkStatusLookup(b *testing.B) {")
+	typeCheck(t, statusSrc, source(t, g), string(tests))
+}
@@ -0,0 +1,182 @@
+package gen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transforms maps the names of the transforms of TypeSpec to functions of
+// constant names.
+var transforms = map[string]func(string) string{
+	"":            func(s string) string { return s },
+	"none":        func(s string) string { return s },
+	"lower":       strings.ToLower,
+	"upper":       strings.ToUpper,
+	"snake":       func(s string) string { return strings.ToLower(snake(s)) },
+	"snake-upper": upperSnake,
+	"kebab":       func(s string) string { return strings.ToLower(kebab(s)) },
+	"kebab-upper": func(s string) string { return strings.ToUpper(kebab(s)) },
+	"camel":       camel,
+}
+
+// transformKey returns the map key of the constant name: the name with
+// prefix trimmed, then transformed.
+func transformKey(name, prefix, transform string) (string, error) {
+	fn, ok := transforms[transform]
+	if !ok {
+		return "", fmt.Errorf("unknown transform %q", transform)
+	}
+	key := fn(strings.TrimPrefix(name, prefix))
+	if key == "" {
+		return "", fmt.Errorf("%s has an empty key", name)
+	}
+	return key, nil
+}
+
+// Key returns the key of the constant named name in the lookups of the type
+// of spec generated with cfg: the name with the prefix of spec trimmed,
+// transformed, affixed and normalized.
+func Key(name string, spec TypeSpec, cfg *Config) (string, error) {
+	key, err := transformKey(name, spec.TrimPrefix, spec.Transform)
+	if err != nil {
+		return "", err
+	}
+	prefix, suffix := spec.affixes(cfg)
+	return cfg.normalize(prefix + key + suffix), nil
+}
+
+// affixes returns the prefix and the suffix of the keys of the type of the
+// spec, those of cfg unless the spec sets its own.
+func (s TypeSpec) affixes(cfg *Config) (prefix, suffix string) {
+	prefix, suffix = s.KeyPrefix, s.KeySuffix
+	if prefix == "" {
+		prefix = cfg.KeyPrefix
+	}
+	if suffix == "" {
+		suffix = cfg.KeySuffix
+	}
+	return prefix, suffix
+}
+
+// normalize returns the key in the Unicode normalization form of Normalize.
+// Identifiers may spell the same letter differently, e.g. as a ligature
+// under NFKC, and keys are matched byte for byte.
+func (c *Config) normalize(key string) string {
+	switch c.Normalize {
+	case "nfc":
+		return norm.NFC.String(key)
+	case "nfkc":
+		return norm.NFKC.String(key)
+	}
+	return key
+}
+
+// upperSnake converts a Go identifier to upper snake case, e.g. HTTPStatus
+// to HTTP_STATUS.
+func upperSnake(s string) string {
+	return strings.ToUpper(snake(s))
+}
+
+// snake converts a Go identifier to snake case, keeping the case of each
+// rune. Words are split at lower-to-upper transitions and before the last
+// upper case rune of an acronym followed by lower case, e.g. HTTPStatus
+// becomes HTTP_Status. Title case runes, such as the digraph ǅ, count as
+// upper case.
+func snake(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && isUpper(r) && runes[i-1] != '_' {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || isUpper(prev) && nextLower {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isUpper reports whether the rune starts a word in upper or title case.
+func isUpper(r rune) bool {
+	return unicode.IsUpper(r) || unicode.IsTitle(r)
+}
+
+// kebab converts a Go identifier to kebab case, keeping the case of each
+// rune.
+func kebab(s string) string {
+	return strings.Replace(snake(s), "_", "-", -1)
+}
+
+// camel converts a Go identifier to lower camel case, e.g. HTTPStatus to
+// httpStatus.
+func camel(s string) string {
+	words := strings.Split(snake(s), "_")
+	for i, w := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(w)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToTitle(r)) + strings.ToLower(w[size:])
+	}
+	return strings.Join(words, "")
+}
+
+// exportedName returns the Go identifier, starting with an upper case
+// letter, of the words of s, e.g. RateLimit for rate-limit.
+func exportedName(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToTitle(r)) + w[size:]
+	}
+	return strings.Join(words, "")
+}
+
+// receiver returns the conventional receiver name of methods of the type
+// named s: its first letter, lower-cased, e.g. s for Status.
+func receiver(s string) string {
+	if i := strings.LastIndex(s, "."); i >= 0 {
+		s = s[i+1:]
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r))
+}
+
+// funcs returns the functions available to templates: string helpers and
+// a lookup function per substitution table of TemplateFuncs, which yields
+// its argument if the table has no entry for it.
+func (c *Config) funcs() template.FuncMap {
+	m := template.FuncMap{
+		"snake":      func(s string) string { return strings.ToLower(snake(s)) },
+		"snakeUpper": upperSnake,
+		"kebab":      func(s string) string { return strings.ToLower(kebab(s)) },
+		"camel":      camel,
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"trimprefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimsuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"quote":      strconv.Quote,
+		"receiver":   receiver,
+	}
+	for name, table := range c.TemplateFuncs {
+		table := table
+		m[name] = func(s string) string {
+			if v, ok := table[s]; ok {
+				return v
+			}
+			return s
+		}
+	}
+	return m
+}
@@ -0,0 +1,152 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransformKey(t *testing.T) {
+	for _, tt := range []struct {
+		name, prefix, transform string
+		want                    string
+	}{
+		{"HTTPStatus", "", "", "HTTPStatus"},
+		{"HTTPStatus", "", "none", "HTTPStatus"},
+		{"HTTPStatus", "", "lower", "httpstatus"},
+		{"HTTPStatus", "", "upper", "HTTPSTATUS"},
+		{"HTTPStatus", "", "snake", "http_status"},
+		{"HTTPStatus", "", "snake-upper", "HTTP_STATUS"},
+		{"HTTPStatus", "", "kebab", "http-status"},
+		{"HTTPStatus", "", "kebab-upper", "HTTP-STATUS"},
+		{"HTTPStatus", "", "camel", "httpStatus"},
+		{"StatusNotFound", "Status", "snake", "not_found"},
+		{"Status2FA", "", "snake", "status2_fa"},
+		{"Already_Snake", "", "snake", "already_snake"},
+		{"ǅemal", "", "kebab", "ǆemal"},
+		{"Xǅemal", "", "snake", "x_ǆemal"},
+	} {
+		got, err := transformKey(tt.name, tt.prefix, tt.transform)
+		if err != nil {
+			t.Errorf("transformKey(%q, %q, %q): %v", tt.name, tt.prefix, tt.transform, err)
+		} else if got != tt.want {
+			t.Errorf("transformKey(%q, %q, %q) = %q, want %q", tt.name, tt.prefix, tt.transform, got, tt.want)
+		}
+	}
+}
+
+func TestTransformKeyErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name, prefix, transform string
+		wantErr                 string
+	}{
+		{"Status", "", "pascal", `unknown transform "pascal"`},
+		{"Status", "Status", "", "Status has an empty key"},
+	} {
+		_, err := transformKey(tt.name, tt.prefix, tt.transform)
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("transformKey(%q, %q, %q): error %v, want %q", tt.name, tt.prefix, tt.transform, err, tt.wantErr)
+		}
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	for _, tt := range []struct{ s, want string }{
+		{"rate-limit", "RateLimit"},
+		{"live", "Live"},
+		{"v2_api", "V2Api"},
+		{"Already", "Already"},
+		{"ümlaut words", "ÜmlautWords"},
+	} {
+		if got := exportedName(tt.s); got != tt.want {
+			t.Errorf("exportedName(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	for _, tt := range []struct{ s, want string }{
+		{"Status", "s"},
+		{"pb.Status", "s"},
+		{"Étape", "é"},
+	} {
+		if got := receiver(tt.s); got != tt.want {
+			t.Errorf("receiver(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for _, tt := range []struct {
+		normalize, key, want string
+	}{
+		{"", "état", "état"},
+		{"none", "état", "état"},
+		{"nfc", "état", "état"},
+		{"nfc", "ﬁle", "ﬁle"},
+		{"nfkc", "état", "état"},
+		{"nfkc", "ﬁle", "file"},
+		{"nfkc", "ＡＢ", "AB"},
+	} {
+		c := &Config{Normalize: tt.normalize}
+		if got := c.normalize(tt.key); got != tt.want {
+			t.Errorf("normalize %q under %q = %q, want %q", tt.key, tt.normalize, got, tt.want)
+		}
+	}
+}
+
+// normalizeSrc declares constants whose names differ, but not once
+// normalized under NFKC: ﬁ is a ligature of f and i.
+const normalizeSrc = `package p
+
+type Doc int
+
+const (
+	ﬁle Doc = iota
+	file
+	ÉtatActif
+)
+`
+
+// TestNormalizeKeys checks the keys of non-ASCII constant names, which
+// must be valid Go string literals, and those colliding once normalized.
+func TestNormalizeKeys(t *testing.T) {
+	out := source(t, load(t, normalizeSrc, &Config{Transform: "snake"}, "Doc"))
+	wantContains(t, out, `"ﬁle":`, `"file":`, `"état_actif": ÉtatActif,`)
+	typeCheck(t, normalizeSrc, out)
+
+	out = source(t, load(t, normalizeSrc, &Config{Normalize: "nfkc", Aliases: "first"}, "Doc"))
+	wantContains(t, out, `"file":`)
+	if strings.Contains(out, `"ﬁle":`) || strings.Contains(out, "file,") {
+		t.Errorf("aliases=first kept file, of the same key as ﬁle:\n%s", out)
+	}
+	typeCheck(t, normalizeSrc, out)
+
+	g, err := LoadSource("src.go", []byte(normalizeSrc), &Config{Normalize: "nfkc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = g.Generate(TypeSpec{Name: "Doc"})
+	if err == nil || !strings.Contains(err.Error(), `file has the same key "file" as ﬁle`) {
+		t.Errorf("error %v, want one of the same key", err)
+	}
+}
+
+func TestKey(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		spec TypeSpec
+		cfg  Config
+		want string
+	}{
+		{"StatusActive", TypeSpec{}, Config{}, "StatusActive"},
+		{"StatusActive", TypeSpec{TrimPrefix: "Status", Transform: "lower"}, Config{}, "active"},
+		{"StatusActive", TypeSpec{Transform: "snake"}, Config{KeyPrefix: "s:", KeySuffix: "!"}, "s:status_active!"},
+		{"StatusActive", TypeSpec{KeyPrefix: "t:"}, Config{KeyPrefix: "s:"}, "t:StatusActive"},
+		{"ﬁle", TypeSpec{}, Config{Normalize: "nfkc"}, "file"},
+	} {
+		got, err := Key(tt.name, tt.spec, &tt.cfg)
+		if err != nil || got != tt.want {
+			t.Errorf("Key(%q, %+v, %+v) = %q, %v, want %q", tt.name, tt.spec, tt.cfg, got, err, tt.want)
+		}
+	}
+}
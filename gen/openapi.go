@@ -0,0 +1,26 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// OpenAPI returns an OpenAPI components section declaring a string schema
+// per generated type, enumerating the keys of its name map.
+func (g *Generator) OpenAPI() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(g.cfg.licenseHeader("#"))
+	buf.WriteString(g.cfg.generatedBy("#"))
+	buf.WriteString("\n")
+	buf.WriteString("components:\n  schemas:\n")
+	for _, data := range g.types {
+		fmt.Fprintf(&buf, "    %s:\n      type: string\n      enum:\n", data.Type)
+		for _, c := range data.Consts {
+			// A JSON string is a valid YAML scalar and needs no further escaping.
+			key, _ := json.Marshal(c.Key)
+			fmt.Fprintf(&buf, "        - %s\n", key)
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,110 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/constant"
+	"math"
+	"path"
+	"strings"
+)
+
+// Proto returns a proto3 file declaring an enum per generated type that
+// mirrors the names and values of its constants. Value names follow the
+// protobuf style guide: upper snake case, prefixed by the enum name.
+func (g *Generator) Proto() (src []byte, err error) {
+	defer catch(&err)
+	pkgName := g.cfg.ProtoPackage
+	if pkgName == "" {
+		pkgName = g.pkg.name
+	}
+	var buf bytes.Buffer
+	buf.WriteString(g.cfg.licenseHeader("//"))
+	buf.WriteString(g.cfg.generatedBy("//"))
+	buf.WriteString("\n")
+	fmt.Fprintf(&buf, "syntax = \"proto3\";\n\npackage %s;\n", pkgName)
+	if g.cfg.ProtoGo != "" {
+		fmt.Fprintf(&buf, "\noption go_package = %q;\n", g.cfg.ProtoGo)
+	}
+	for _, data := range g.types {
+		fmt.Fprintf(&buf, "\nenum %s {\n", data.Type)
+		if len(data.Unique) != len(data.Consts) {
+			buf.WriteString("  option allow_alias = true;\n")
+		}
+		// proto3 requires the first value to be zero: the first zero
+		// constant moves up, or else an UNSPECIFIED value is added.
+		consts := data.Consts
+		zero := -1
+		for i, c := range consts {
+			if c.Value != nil && c.Value.Kind() == constant.Int && constant.Sign(c.Value) == 0 {
+				zero = i
+				break
+			}
+		}
+		if zero < 0 {
+			fmt.Fprintf(&buf, "  %s_UNSPECIFIED = 0;\n", upperSnake(data.Type))
+		} else {
+			consts = append([]Value{consts[zero]}, consts[:zero]...)
+			consts = append(consts, data.Consts[zero+1:]...)
+		}
+		for _, c := range consts {
+			n, ok := protoValue(c.Value)
+			if !ok {
+				fatalf("%s: value cannot be represented in a protobuf enum", c.Name)
+			}
+			fmt.Fprintf(&buf, "  %s = %d;\n", protoName(data.Type, data.Type, c), n)
+		}
+		buf.WriteString("}\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// protoValue returns the constant value as an enum number; protobuf enums are
+// int32.
+func protoValue(v constant.Value) (int64, bool) {
+	if v == nil || v.Kind() != constant.Int {
+		return 0, false
+	}
+	n, exact := constant.Int64Val(v)
+	return n, exact && n >= math.MinInt32 && n <= math.MaxInt32
+}
+
+// protoName returns the name of the value of the protobuf enum named
+// enumType for the constant of the Go type typeName: that of its
+// //mapconst:proto= directive or else, as the protobuf style guide has it,
+// the constant name without the type name, in upper snake case and
+// prefixed by the enum name, e.g. STATUS_ACTIVE for StatusActive.
+func protoName(enumType, typeName string, c Value) string {
+	if c.Proto != "" {
+		return c.Proto
+	}
+	name := strings.TrimPrefix(c.Name, typeName)
+	if name == "" {
+		name = c.Name
+	}
+	return upperSnake(enumType) + "_" + upperSnake(name)
+}
+
+// protoConst pairs a constant with the constant of the protobuf enum it
+// converts to.
+type protoConst struct {
+	Name  string // The name of the constant.
+	Proto string // The qualified name of the Go constant of the protobuf enum value.
+}
+
+// protoEnum splits ProtoEnum into the import path, the package name and the
+// type of the protobuf enum.
+func (c *Config) protoEnum() (importPath, pkgName, typ string) {
+	i := strings.LastIndex(c.ProtoEnum, ".")
+	if i < 0 || i < strings.LastIndex(c.ProtoEnum, "/") {
+		return c.ProtoEnum, path.Base(c.ProtoEnum), ""
+	}
+	importPath, typ = c.ProtoEnum[:i], c.ProtoEnum[i+1:]
+	return importPath, path.Base(importPath), typ
+}
+
+// protoGoName returns the package name of the Go code generated from the
+// proto file.
+func (c *Config) protoGoName() string {
+	return path.Base(strings.SplitN(c.ProtoGo, ";", 2)[0])
+}
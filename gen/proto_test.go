@@ -0,0 +1,68 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProto(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		src   string
+		cfg   Config
+		types string
+		want  []string
+	}{
+		{"package", statusSrc, Config{ProtoPackage: "acme.v1"}, "Status", []string{"package acme.v1;\n", "  STATUS_ACTIVE = 0;\n  STATUS_PENDING = 1;\n"}},
+		{"go package", statusSrc, Config{ProtoGo: "example.com/pb"}, "Status", []string{"option go_package = \"example.com/pb\";\n"}},
+		{"zero first", statusSrc, Config{}, "Level", []string{"  option allow_alias = true;\n  LEVEL_INFO = 0;\n  LEVEL_DEBUG = -1;\n"}},
+		{"unspecified", "package p\n\ntype Bit uint\n\nconst (\n\tBitA Bit = 1 << iota\n\tBitB\n)\n", Config{}, "Bit", []string{"  BIT_UNSPECIFIED = 0;\n  BIT_A = 1;\n  BIT_B = 2;\n"}},
+		{"directive", "package p\n\ntype Bit uint\n\nconst (\n\t//mapconst:proto=BIT_NONE\n\tBitNone Bit = iota\n)\n", Config{}, "Bit", []string{"  BIT_NONE = 0;\n"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			out, err := load(t, tt.src, &cfg, tt.types).Proto()
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantContains(t, string(out), tt.want...)
+		})
+	}
+}
+
+func TestProtoErrors(t *testing.T) {
+	_, err := load(t, statusSrc, nil, "Region").Proto()
+	if err == nil || !strings.Contains(err.Error(), "USEast: value cannot be represented in a protobuf enum") {
+		t.Errorf("error %v", err)
+	}
+}
+
+func TestProtoEnumConfig(t *testing.T) {
+	for _, tt := range []struct {
+		protoEnum                string
+		importPath, pkgName, typ string
+		wantErr                  bool
+	}{
+		{"example.com/pb.Status", "example.com/pb", "pb", "Status", false},
+		{"example.com/v1.2/pb.Status", "example.com/v1.2/pb", "pb", "Status", false},
+		{"pb.Status", "pb", "pb", "Status", false},
+		{"Status", "Status", "Status", "", true},
+		{"example.com/pb", "example.com/pb", "pb", "", true},
+		{"example.com/v1.2/pb", "example.com/v1.2/pb", "pb", "", true},
+		{".Status", "", ".", "Status", true},
+		{"example.com/pb.", "example.com/pb", "pb", "", true},
+		{"example.com/pb.Status-1", "example.com/pb", "pb", "Status-1", true},
+	} {
+		c := &Config{ProtoEnum: tt.protoEnum}
+		importPath, pkgName, typ := c.protoEnum()
+		if importPath != tt.importPath || pkgName != tt.pkgName || typ != tt.typ {
+			t.Errorf("protoEnum of %q = %q, %q, %q, want %q, %q, %q", tt.protoEnum, importPath, pkgName, typ, tt.importPath, tt.pkgName, tt.typ)
+		}
+		err := c.validate()
+		if tt.wantErr != (err != nil) {
+			t.Errorf("validate of %q: %v", tt.protoEnum, err)
+		} else if err != nil && !strings.Contains(err.Error(), "must be an import path and a type") {
+			t.Errorf("validate of %q: %v", tt.protoEnum, err)
+		}
+	}
+}
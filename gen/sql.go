@@ -0,0 +1,41 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// SQLDDL returns a migration snippet constraining database values to the
+// keys of each generated type's name map: an enum type for postgres, and a
+// CHECK constraint on a column named after the type for mysql and sqlite.
+func (g *Generator) SQLDDL(dialect string) ([]byte, error) {
+	switch dialect {
+	case "postgres", "mysql", "sqlite":
+	default:
+		return nil, fmt.Errorf("unknown SQL dialect %s; must be postgres, mysql or sqlite", dialect)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(g.cfg.licenseHeader("--"))
+	buf.WriteString(g.cfg.generatedBy("--"))
+	for _, data := range g.types {
+		name := strings.ToLower(snake(data.Type))
+		keys := make([]string, len(data.Consts))
+		for i, c := range data.Consts {
+			keys[i] = sqlQuote(c.Key)
+		}
+		switch dialect {
+		case "postgres":
+			fmt.Fprintf(&buf, "\nCREATE TYPE %s AS ENUM (%s);\n", name, strings.Join(keys, ", "))
+		case "mysql", "sqlite":
+			fmt.Fprintf(&buf, "\n-- Add to the definition of the table holding the %s column.\n", name)
+			fmt.Fprintf(&buf, "CONSTRAINT %[1]s_check CHECK (%[1]s IN (%[2]s))\n", name, strings.Join(keys, ", "))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// sqlQuote returns s as a SQL string literal.
+func sqlQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
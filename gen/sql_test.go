@@ -0,0 +1,28 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSQLDDL(t *testing.T) {
+	for _, tt := range []struct {
+		dialect string
+		want    string
+	}{
+		{"postgres", "CREATE TYPE status AS ENUM ('Active', 'Pending', 'Disabled', 'Inactive');\n"},
+		{"mysql", "CONSTRAINT status_check CHECK (status IN ('Active', 'Pending', 'Disabled', 'Inactive'))\n"},
+		{"sqlite", "CONSTRAINT status_check CHECK (status IN ('Active', 'Pending', 'Disabled', 'Inactive'))\n"},
+	} {
+		out, err := load(t, statusSrc, nil, "Status").SQLDDL(tt.dialect)
+		if err != nil {
+			t.Errorf("%s: %v", tt.dialect, err)
+			continue
+		}
+		wantContains(t, string(out), "-- Code generated by", tt.want)
+	}
+	_, err := load(t, statusSrc, nil, "Status").SQLDDL("oracle")
+	if err == nil || !strings.Contains(err.Error(), "unknown SQL dialect oracle") {
+		t.Errorf("oracle: error %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Swag returns, per generated type, the enums struct tag and the Enums
+// attribute of @Param annotations of github.com/swaggo/swag listing the keys
+// of its name map, for documenting fields and parameters holding the type.
+func (g *Generator) Swag() (src []byte, err error) {
+	defer catch(&err)
+	var buf bytes.Buffer
+	buf.WriteString(g.cfg.licenseHeader("//"))
+	buf.WriteString(g.cfg.generatedBy("//"))
+	for _, data := range g.types {
+		keys := make([]string, len(data.Consts))
+		for i, c := range data.Consts {
+			if strings.ContainsAny(c.Key, `,()"`) {
+				fatalf("%s: name %q cannot be listed in swag annotations", c.Name, c.Key)
+			}
+			keys[i] = c.Key
+		}
+		name := strings.ToLower(snake(data.Type))
+		fmt.Fprintf(&buf, "\n// %s\n", data.Type)
+		fmt.Fprintf(&buf, "`enums:\"%s\"`\n", strings.Join(keys, ","))
+		fmt.Fprintf(&buf, "// @Param %s query string false \"%s\" Enums(%s)\n", name, data.Type, strings.Join(keys, ", "))
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,61 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/constant"
+	"regexp"
+	"strconv"
+)
+
+// tsIdentifier matches the property names TypeScript allows unquoted.
+var tsIdentifier = regexp.MustCompile(`^[$_A-Za-z][$_0-9A-Za-z]*$`)
+
+// TypeScript returns the generated types as TypeScript const objects mapping
+// the key of each constant in the name map, as trimmed and transformed, to
+// its value, each paired with a type of the same name that is the union of
+// the values.
+func (g *Generator) TypeScript() (src []byte, err error) {
+	defer catch(&err)
+	var buf bytes.Buffer
+	buf.WriteString(g.cfg.licenseHeader("//"))
+	buf.WriteString(g.cfg.generatedBy("//"))
+	for _, data := range g.types {
+		fmt.Fprintf(&buf, "\nexport const %s = {\n", data.Type)
+		for _, c := range data.Consts {
+			lit, err := jsLiteral(c.Value)
+			if err != nil {
+				fatalf("%s: %s", c.Name, err)
+			}
+			key := c.Key
+			if !tsIdentifier.MatchString(key) {
+				b, _ := json.Marshal(key)
+				key = string(b)
+			}
+			fmt.Fprintf(&buf, "  %s: %s,\n", key, lit)
+		}
+		fmt.Fprintf(&buf, "} as const;\n\nexport type %[1]s = (typeof %[1]s)[keyof typeof %[1]s];\n", data.Type)
+	}
+	return buf.Bytes(), nil
+}
+
+// jsLiteral returns the JavaScript literal of a constant value.
+func jsLiteral(v constant.Value) (string, error) {
+	if v == nil {
+		return "", fmt.Errorf("value cannot be resolved")
+	}
+	switch v.Kind() {
+	case constant.Bool:
+		return strconv.FormatBool(constant.BoolVal(v)), nil
+	case constant.String:
+		b, err := json.Marshal(constant.StringVal(v))
+		return string(b), err
+	case constant.Int:
+		return v.ExactString(), nil
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	}
+	return "", fmt.Errorf("value %s has no JavaScript representation", v)
+}
@@ -0,0 +1,41 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypeScript(t *testing.T) {
+	const src = `package p
+
+type Pill int
+
+const (
+	PillPlacebo Pill = iota
+	PillHTTPDose
+)
+`
+	for _, tt := range []struct {
+		name string
+		cfg  Config
+		want []string
+	}{
+		{"names", Config{}, []string{"  PillPlacebo: 0,", "  PillHTTPDose: 1,"}},
+		{"trimprefix", Config{TrimPrefix: "Pill"}, []string{"  Placebo: 0,", "  HTTPDose: 1,"}},
+		{"transform", Config{TrimPrefix: "Pill", Transform: "snake"}, []string{"  placebo: 0,", "  http_dose: 1,"}},
+		{"quoted", Config{TrimPrefix: "Pill", Transform: "kebab", KeyPrefix: "pill:"}, []string{`  "pill:placebo": 0,`, `  "pill:http-dose": 1,`}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := load(t, src, &tt.cfg, "Pill")
+			out, err := g.TypeScript()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(out), want+"\n") {
+					t.Errorf("output lacks %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
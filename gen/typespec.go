@@ -0,0 +1,104 @@
+package gen
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// TypeSpec is a type to generate, with the options that apply to it.
+type TypeSpec struct {
+	Name       string
+	TrimPrefix string // Prefix trimmed from the constant names to form keys.
+	Transform  string // Transform of the trimmed names to keys.
+	KeyPrefix  string // Prefix added to the keys after the transform; default Config.KeyPrefix.
+	KeySuffix  string // Suffix added to the keys after the transform; default Config.KeySuffix.
+}
+
+// ParseTypeSpecs parses a list of types to generate: ";"-separated entries,
+// each either a comma-separated list of type names, or a single type name
+// followed by a colon and comma-separated option=value pairs overriding the
+// default trimPrefix and transform for that type, or setting its keyprefix
+// and keysuffix, e.g.
+//
+//	Pill:trimprefix=Pill,transform=snake;Drug:transform=upper,keyprefix=drug:
+//
+// Empty entries and names, as left by stray separators, are ignored; names
+// that are not identifiers, possibly qualified by a package name, and types
+// listed twice are errors.
+func ParseTypeSpecs(s, trimPrefix, transform string) ([]TypeSpec, error) {
+	var specs []TypeSpec
+	seen := make(map[string]bool)
+	add := func(spec TypeSpec) error {
+		if !validTypeName(spec.Name) {
+			return fmt.Errorf("invalid type name %q", spec.Name)
+		}
+		if seen[spec.Name] {
+			return fmt.Errorf("type %s is listed twice", spec.Name)
+		}
+		seen[spec.Name] = true
+		specs = append(specs, spec)
+		return nil
+	}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, options := entry, ""
+		if i := strings.Index(entry, ":"); i >= 0 {
+			name, options = strings.TrimSpace(entry[:i]), entry[i+1:]
+		}
+		if options == "" {
+			for _, name := range strings.Split(name, ",") {
+				if name = strings.TrimSpace(name); name == "" {
+					continue
+				}
+				if err := add(TypeSpec{Name: name, TrimPrefix: trimPrefix, Transform: transform}); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if name == "" {
+			return nil, fmt.Errorf("options %q follow no type name", options)
+		}
+		spec := TypeSpec{Name: name, TrimPrefix: trimPrefix, Transform: transform}
+		for _, option := range strings.Split(options, ",") {
+			kv := strings.SplitN(option, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("type %s: option %q is not of the form option=value", name, option)
+			}
+			switch key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]); key {
+			case "trimprefix":
+				spec.TrimPrefix = value
+			case "transform":
+				spec.Transform = value
+			case "keyprefix":
+				spec.KeyPrefix = value
+			case "keysuffix":
+				spec.KeySuffix = value
+			default:
+				return nil, fmt.Errorf("type %s: unknown option %q", name, key)
+			}
+		}
+		if err := add(spec); err != nil {
+			return nil, err
+		}
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no type names in %q", s)
+	}
+	return specs, nil
+}
+
+// validTypeName reports whether the type name is an identifier, possibly
+// qualified by a package name.
+func validTypeName(name string) bool {
+	for _, part := range strings.SplitN(name, ".", 2) {
+		if !token.IsIdentifier(part) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package gen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTypeSpecs(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		want    []TypeSpec
+		wantErr string
+	}{
+		{in: "Status", want: []TypeSpec{{Name: "Status", TrimPrefix: "X", Transform: "lower"}}},
+		{in: "Status,Color", want: []TypeSpec{
+			{Name: "Status", TrimPrefix: "X", Transform: "lower"},
+			{Name: "Color", TrimPrefix: "X", Transform: "lower"},
+		}},
+		{in: "kinds.Kind", want: []TypeSpec{{Name: "kinds.Kind", TrimPrefix: "X", Transform: "lower"}}},
+		{in: "Pill:trimprefix=Pill,transform=snake;Drug:transform=upper,keyprefix=drug:", want: []TypeSpec{
+			{Name: "Pill", TrimPrefix: "Pill", Transform: "snake"},
+			{Name: "Drug", TrimPrefix: "X", Transform: "upper", KeyPrefix: "drug:"},
+		}},
+		{in: " Pill : keysuffix = _k ", want: []TypeSpec{{Name: "Pill", TrimPrefix: "X", Transform: "lower", KeySuffix: "_k"}}},
+		{in: "Pill:", want: []TypeSpec{{Name: "Pill", TrimPrefix: "X", Transform: "lower"}}},
+		// Stray separators.
+		{in: "Status;", want: []TypeSpec{{Name: "Status", TrimPrefix: "X", Transform: "lower"}}},
+		{in: ";Status,,Color,", want: []TypeSpec{
+			{Name: "Status", TrimPrefix: "X", Transform: "lower"},
+			{Name: "Color", TrimPrefix: "X", Transform: "lower"},
+		}},
+		// Errors.
+		{in: "", wantErr: "no type names"},
+		{in: " ; , ", wantErr: "no type names"},
+		{in: ":transform=snake", wantErr: "follow no type name"},
+		{in: "Status,Status", wantErr: "Status is listed twice"},
+		{in: "Status;Status:transform=snake", wantErr: "Status is listed twice"},
+		{in: "Status Color", wantErr: `invalid type name "Status Color"`},
+		{in: "Pill,Drug:transform=snake", wantErr: `invalid type name "Pill,Drug"`},
+		{in: "a.b.C", wantErr: "invalid type name"},
+		{in: "Pill:transform", wantErr: "not of the form option=value"},
+		{in: "Pill:transform=snake,", wantErr: "not of the form option=value"},
+		{in: "Pill:color=red", wantErr: `unknown option "color"`},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			specs, err := ParseTypeSpecs(tt.in, "X", "lower")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(specs, tt.want) {
+				t.Errorf("got %+v, want %+v", specs, tt.want)
+			}
+		})
+	}
+}
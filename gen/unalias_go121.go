@@ -0,0 +1,12 @@
+//go:build !go1.22
+// +build !go1.22
+
+package gen
+
+import "go/types"
+
+// unalias returns the type itself: before Go 1.22, go/types resolves the
+// aliases it meets, so no type denotes one.
+func unalias(t types.Type) types.Type {
+	return t
+}
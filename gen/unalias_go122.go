@@ -0,0 +1,11 @@
+//go:build go1.22
+// +build go1.22
+
+package gen
+
+import "go/types"
+
+// unalias returns the type denoted by an alias, or the type itself.
+func unalias(t types.Type) types.Type {
+	return types.Unalias(t)
+}
@@ -0,0 +1,71 @@
+package gen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// workFile returns the go.work file in effect in the absolute directory:
+// that of $GOWORK, or else the first found up the directory tree. It
+// returns "" outside a workspace.
+func workFile(abs string) string {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return ""
+	case "":
+	default:
+		return gowork
+	}
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "go.work")); err == nil {
+			return filepath.Join(dir, "go.work")
+		}
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+// workspaceModules returns the directories of the modules that the
+// workspace of the absolute directory uses, or nil outside a workspace.
+func workspaceModules(abs string) []string {
+	work := workFile(abs)
+	if work == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(work)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	block := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case block && fields[0] == ")":
+			block = false
+			continue
+		case block:
+		case fields[0] == "use" && len(fields) == 2 && fields[1] == "(":
+			block = true
+			continue
+		case fields[0] == "use" && len(fields) == 2:
+			fields = fields[1:]
+		default:
+			continue
+		}
+		dir := strings.Trim(fields[0], "\"`")
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(work), dir)
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
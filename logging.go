@@ -0,0 +1,36 @@
+package main
+
+import (
+	"log"
+	"os"
+)
+
+// Messages go to standard error, where they never mix with generated code
+// printed to standard output, each tagged with its level so that scripts
+// can tell warnings from errors: info, logged with -v, warning, left out with
+// -q, and error.
+
+// infof logs progress details if -v is set, unless -q is set too.
+func infof(format string, args ...interface{}) {
+	if config.verbose && !config.quiet {
+		log.Printf("info: "+format, args...)
+	}
+}
+
+// warnf logs a warning unless -q is set.
+func warnf(format string, args ...interface{}) {
+	if !config.quiet {
+		log.Printf("warning: "+format, args...)
+	}
+}
+
+// errorf logs an error.
+func errorf(format string, args ...interface{}) {
+	log.Printf("error: "+format, args...)
+}
+
+// fatalf logs an error and exits with status 1.
+func fatalf(format string, args ...interface{}) {
+	errorf(format, args...)
+	os.Exit(1)
+}
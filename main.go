@@ -1,280 +1,794 @@
+//go:build go1.5
 // +build go1.5
 
 package main
 
 import (
-	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"go/ast"
 	"go/build"
-	"go/format"
-	"go/parser"
-	"go/token"
-	"go/types"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
-	"text/template"
-)
-
-var headerTmpl string = `// Code generated by \"mapconst %[1]s\"; DO NOT EDIT"
-
-package %[2]s
-`
 
-type mapConstData struct {
-	Type   string
-	Consts []string
-}
-
-var mapConstTpl string = `
-var {{.Type}}NameToValue = map[string]{{.Type}} {
-	{{range .Consts}} "{{.}}":{{.}},
-	{{end}}
-}
-`
+	"github.com/empirefox/mapconst/gen"
+)
 
 var (
+	// cfg holds the options of the generated code.
+	cfg gen.Config
+
 	config struct {
-		typeNames string
-		output    string
+		typeNames     string
+		output        string
+		outputDir     string
+		pkgName       string
+		testPkg       bool
+		ts            string
+		openapi       string
+		graphql       string
+		md            string
+		csv           string
+		kubebuilder   string
+		swag          string
+		report        string
+		mod           string
+		proto         string
+		sqlDDL        string
+		sqlOutput     string
+		header        string
+		templateFuncs string
+		headerTpl     string
+		force         bool
+		strict        bool
+		ignoreMissing bool
+		verbose       bool
+		quiet         bool
+		version       bool
+		diff          bool
+		diffOnly      bool
+		verify        bool // Set by the verify subcommand: compare instead of writing.
+		variants      string
+		emits         []string // The -emit flags, emitter=filename.
+		variant       string   // Suffix of the output files of the variant being generated, e.g. "_linux".
+		flags         []string // The flags of the command line, without the neutral ones.
 	}
 )
 
 func init() {
-	flag.StringVar(&config.typeNames, "type", "", "comma-separated list of type names; must be set")
+	flag.Var(typesFlag{&config.typeNames}, "type", "comma-separated list of type names, which may be qualified as pkg.Kind, or Type:option=value,... to set -trimprefix, -transform, -keyprefix or -keysuffix per type; repeatable; default the types of the //mapconst:types=T,... [option=value ...] directives of the package or, under go generate, the type of the const declaration following the directive")
+	flag.StringVar(&cfg.TrimPrefix, "trimprefix", "", "prefix to trim from the constant names to form map keys")
+	flag.StringVar(&cfg.Transform, "transform", "none", "transform of the trimmed constant names to map keys: none, lower, upper, snake, snake-upper, kebab, kebab-upper, camel")
+	flag.StringVar(&cfg.KeyPrefix, "keyprefix", "", "prefix added to the map keys after the transform, e.g. status: for status:active")
+	flag.StringVar(&cfg.KeySuffix, "keysuffix", "", "suffix added to the map keys after the transform")
 	flag.StringVar(&config.output, "output", "", "output file name; default srcdir/<type>_mapconst.go")
+	flag.StringVar(&config.outputDir, "output-dir", "", "directory of the generated file; default srcdir")
+	flag.StringVar(&config.pkgName, "pkg", "", "package name of the generated file; default the package in output-dir, or the source package")
+	flag.BoolVar(&config.testPkg, "testpackage", false, "generate into the external test package as srcdir/<type>_mapconst_test.go")
+	flag.StringVar(&cfg.Aliases, "aliases", "error", "what to do with constants whose names map to the same key, e.g. after -transform: error, or first to keep the first declared")
+	flag.StringVar(&cfg.Normalize, "normalize", "none", "Unicode normalization of the map keys: none, nfc or nfkc; nfkc also folds compatibility forms such as ligatures and full-width letters")
+	flag.StringVar(&cfg.Lookup, "lookup", "map", "how names are looked up: map, switch, perfecthash or binarysearch; the map is a variable, the others are functions")
+	flag.BoolVar(&cfg.NoAlloc, "noalloc", false, "avoid package-level maps, e.g. for TinyGo and WebAssembly; implies -lookup=switch unless perfecthash or binarysearch")
+	flag.StringVar(&cfg.VarName, "varname", "", "template of the name lookup identifier, e.g. '{{.Type}}ByName'; default <type>NameToValue, or <type>FromName unless -lookup=map")
+	flag.StringVar(&cfg.VarName, "name", "", "same as -varname")
+	flag.StringVar(&config.templateFuncs, "template-funcs", "", "JSON file of substitution tables, e.g. {\"short\": {\"Status\": \"St\"}}, each a function of the same name in templates")
+	flag.BoolVar(&cfg.Private, "private", false, "make the generated variables and functions unexported")
+	flag.BoolVar(&cfg.NameMap, "namemap", false, "also generate <type>Names, a map of the values to their names")
+	flag.BoolVar(&cfg.SkipDeprecated, "skip-deprecated", false, "leave out constants documented as deprecated by a \"Deprecated: \" paragraph")
+	flag.BoolVar(&cfg.Deprecated, "deprecated", false, "also generate <type>Deprecated, a map of the values of deprecated constants to the deprecation messages")
+	flag.BoolVar(&cfg.Groups, "groups", false, "also generate <type>Groups, the constants by the groups of their //mapconst:group=name,... directives, and <type>In<group> functions")
+	flag.BoolVar(&cfg.Constraint, "constraint", false, "also generate <type>Like, the constraint ~underlying of each type, and Enum, the union of all the types generated (Go 1.18+)")
+	flag.BoolVar(&cfg.Register, "register", false, "also register each type at init with the package github.com/empirefox/mapconst/registry, which lists and looks up the enums of a program")
+	flag.BoolVar(&cfg.Registry, "registry", false, "also generate EnumRegistry, a map of the names of all the types generated to maps of their names to their constants, and LookupEnum")
+	flag.BoolVar(&cfg.Pairs, "pairs", false, "also generate <type>Pairs, a slice of the names and constants in declaration order")
+	flag.BoolVar(&cfg.Infos, "infos", false, "also generate <type>Infos, a slice of <type>Info describing each constant by its name, value, doc comment, groups and deprecation")
+	flag.BoolVar(&cfg.Exhaustive, "exhaustive", false, "also generate a map literal of all the constants, which the exhaustive linter run with -check=switch,map reports once a constant is added without regenerating")
+	flag.BoolVar(&cfg.Assert, "assert", false, "also generate compile-time assertions of the values of the constants, which break the build once they change without regenerating; requires integer values")
+	flag.BoolVar(&cfg.Count, "count", false, "also generate <type>Count, the number of distinct values")
+	flag.BoolVar(&cfg.Contiguous, "contiguous", false, "fail unless the values are exactly 0 to <type>Count-1; implies -count")
+	flag.BoolVar(&cfg.I18n, "i18n", false, "also generate <type>I18nKeys, a map of the values to translation keys")
+	flag.StringVar(&cfg.I18nPattern, "i18n-pattern", "", "template of the -i18n translation keys with .Type, .Name and .Key; default '{{snake .Type}}.{{snake .Key}}'")
+	flag.BoolVar(&cfg.MustParse, "mustparse", false, "also generate MustParse<type>, which panics listing the valid names for unknown ones")
+	flag.BoolVar(&cfg.Env, "env", false, "also generate <type>FromEnv, parsing an environment variable case-insensitively")
+	flag.BoolVar(&cfg.HTTP, "http", false, "also generate <type>FromQuery and <type>FromPath (Go 1.22+), parsing request parameters, and Invalid<type>Error listing the allowed names")
+	flag.BoolVar(&cfg.ValueMap, "valuemap", false, "also generate <type>ByValue, a map of the values as literals, e.g. \"200\" or \"us-east-1\", to the constants")
+	flag.BoolVar(&cfg.DocConsts, "doc-consts", false, "list the constants in the doc comment of the name lookup")
+	flag.BoolVar(&cfg.Lazy, "lazy", false, "build maps on first use with sync.OnceValue (Go 1.21+); the map variable becomes an accessor function")
+	flag.StringVar(&config.header, "header", "", "file holding a license or copyright notice to put at the top of every output file")
+	flag.StringVar(&config.headerTpl, "header-template", "", "file holding the template of the comments before the package clause of generated Go files, with .Package, .Command, .Args, .Version, .Timestamp and .Hash")
+	flag.BoolVar(&cfg.Append, "append", false, "merge the code of the types into the existing output file, replacing their previous code and keeping that of other types; use -output to share one file")
+	flag.BoolVar(&cfg.EmitGenerate, "emit-generate", false, "add a //go:generate directive reproducing the command line, with its paths made relative to the directory of the generated Go file, to that file")
+	flag.BoolVar(&cfg.Timestamp, "timestamp", false, "make the time of generation available to -header-template as .Timestamp")
+	flag.StringVar(&cfg.BuildTags, "buildtags", "", "build constraint of the generated Go files: comma-separated tags that must all hold, or a //go:build expression")
+	flag.BoolVar(&config.diff, "diff", false, "print a unified diff of the changes to each output file before writing it")
+	flag.BoolVar(&config.diffOnly, "diff-only", false, "print a unified diff of the changes to each output file instead of writing it")
+	flag.BoolVar(&config.force, "force", false, "overwrite output files even if they were not generated by mapconst")
+	flag.BoolVar(&config.strict, "strict", false, "write nothing if any type fails, and fail types with a method to generate already declared by hand; by default the others are still generated and such methods skipped")
+	flag.BoolVar(&config.ignoreMissing, "ignore-missing", false, "warn about and skip types without constants instead of failing")
+	flag.BoolVar(&config.verbose, "v", false, "log the files parsed and which constants are generated or skipped, and why")
+	flag.BoolVar(&config.quiet, "q", false, "log errors only, e.g. in scripts; same as -quiet")
+	flag.BoolVar(&config.quiet, "quiet", false, "log errors only, e.g. in scripts")
+	flag.BoolVar(&config.version, "version", false, "print the version of mapconst and exit")
+	flag.StringVar(&cfg.GOOS, "goos", "", "GOOS whose files are loaded; default $GOOS or the host's")
+	flag.StringVar(&cfg.GOARCH, "goarch", "", "GOARCH whose files are loaded; default $GOARCH or the host's")
+	flag.Var(emitsFlag{&config.emits}, "emit", "also write the output of an emitter to a file, as emitter=filename: one of "+strings.Join(gen.EmitterNames(), ", ")+", or exec:program to run a program given the file name and, as JSON on standard input, the types and the \"Code generated\" line to start the file with; repeatable")
+	flag.StringVar(&config.variants, "variants", "", "comma-separated list of platforms, goos or goos/goarch, e.g. linux,windows,darwin/arm64, to load and generate separately into <type>_mapconst_<goos>[_<goarch>].go, for constants declared in build-constrained files; other platforms get no generated code")
+	flag.StringVar(&config.mod, "mod", "", "module download mode of the go command resolving packages: readonly, vendor or mod; vendor loads dependencies from the vendor directory only")
+	flag.BoolVar(&cfg.Cgo, "cgo", false, "run cgo on packages importing \"C\" to resolve constants defined by C; needs a C compiler")
+	flag.StringVar(&cfg.Binary, "binary", "", "generate MarshalBinary/UnmarshalBinary encoding the constant name or value; one of name, value")
+	flag.BoolVar(&cfg.Msgpack, "msgpack", false, "generate EncodeMsgpack/DecodeMsgpack (github.com/vmihailenco/msgpack/v5) encoding the constant name")
+	flag.BoolVar(&cfg.BSON, "bson", false, "generate MarshalBSONValue/UnmarshalBSONValue (go.mongodb.org/mongo-driver) encoding the constant name")
+	flag.StringVar(&config.ts, "ts", "", "also write the types as TypeScript const objects to the named file")
+	flag.StringVar(&config.openapi, "openapi", "", "also write an OpenAPI components section with an enum schema per type to the named file")
+	flag.StringVar(&config.md, "md", "", "also write a Markdown table per type documenting the constants to the named file")
+	flag.StringVar(&config.kubebuilder, "kubebuilder", "", "also write a +kubebuilder:validation:Enum marker per type, listing the map keys, to the named file")
+	flag.StringVar(&config.swag, "swag", "", "also write the swaggo enums struct tag and @Param Enums attribute per type, listing the map keys, to the named file")
+	flag.StringVar(&config.csv, "csv", "", "also write the type, name, value and comment of every constant to the named CSV file, after # comment lines; tab-separated if it ends in .tsv")
+	flag.StringVar(&config.graphql, "graphql", "", "also write a GraphQL enum per type to the named file")
+	flag.BoolVar(&cfg.SQLNull, "sqlnull", false, "generate Null<type>, a nullable wrapper implementing sql.Scanner and driver.Valuer with the constant name")
+	flag.BoolVar(&cfg.Validator, "validator", false, "generate Register<type>Validation for github.com/go-playground/validator, registering the snake-cased type name as a tag, and <type>OneOf")
+	flag.BoolVar(&cfg.TestGen, "testgen", false, "generate Random<type> and a Generate method implementing testing/quick.Generator, drawing valid constants")
+	flag.BoolVar(&cfg.Navigation, "navigation", false, "generate <type>Min, <type>Max and the methods Next, Prev and Ordinal; requires contiguous integer values")
+	flag.BoolVar(&cfg.Lenient, "lenient", false, "let the generated unmarshaling methods decode unknown names as the constant marked by a //mapconst:default comment; requires a flag generating them, such as -json")
+	flag.BoolVar(&cfg.Pgx, "pgx", false, "generate Register<type> for github.com/jackc/pgx/v5 mapping the Postgres enum type of -sqlddl, and ScanText/TextValue with the constant name")
+	flag.BoolVar(&cfg.Gorm, "gorm", false, "generate GormDataType/GormDBDataType (gorm.io/gorm) and Scan/Value storing the constant name")
+	flag.BoolVar(&cfg.Ent, "ent", false, "generate Values for entgo.io enum fields, Scan/Value storing the constant name and <type>Validator")
+	flag.BoolVar(&cfg.CBOR, "cbor", false, "generate MarshalCBOR/UnmarshalCBOR (github.com/fxamacker/cbor/v2) encoding the constant name as a text string")
+	flag.BoolVar(&cfg.CBORNumeric, "cbor-numeric", false, "let UnmarshalCBOR also accept the value of the constant as a CBOR number; requires -cbor")
+	flag.BoolVar(&cfg.TOML, "toml", false, "generate MarshalTOML/UnmarshalTOML of the go-toml and BurntSushi/toml interfaces encoding the constant name")
+	flag.BoolVar(&cfg.XML, "xml", false, "generate MarshalXML/UnmarshalXML and MarshalXMLAttr/UnmarshalXMLAttr encoding the constant name in elements and attributes")
+	flag.BoolVar(&cfg.Slog, "slog", false, "generate LogValue implementing slog.LogValuer (Go 1.21+) with the constant name")
+	flag.BoolVar(&cfg.JSON, "json", false, "generate MarshalJSON/UnmarshalJSON encoding the constant name")
+	flag.BoolVar(&cfg.JSONNumeric, "json-numeric", false, "let UnmarshalJSON also accept the value of the constant as a JSON number; requires -json")
+	flag.BoolVar(&cfg.GQLGen, "gqlgen", false, "generate MarshalGQL/UnmarshalGQL for gqlgen encoding the constant name")
+	flag.StringVar(&config.proto, "proto", "", "also write a proto3 enum per type to the named file")
+	flag.StringVar(&cfg.ProtoPackage, "proto-package", "", "protobuf package of the -proto file; default the Go package name")
+	flag.StringVar(&cfg.ProtoGo, "proto-go", "", "import path of the Go code generated from the -proto file; generates <type>ToProto/<type>FromProto conversions")
+	flag.StringVar(&cfg.ProtoEnum, "proto-enum", "", "existing protobuf enum, as import path and type, e.g. example.com/pb.Status; generates <type>ToProto/<type>FromProto matching names, overridden by //mapconst:proto=NAME")
+	flag.StringVar(&config.sqlDDL, "sqlddl", "", "also write SQL DDL restricting values to the map keys; one of postgres, mysql, sqlite")
+	flag.StringVar(&config.sqlOutput, "sqlddl-output", "", "file name of the -sqlddl output; default srcdir/<type>_mapconst.sql")
+	flag.StringVar(&config.report, "report", "", "print the types generated, their constants and the files written to standard output; json is the only format")
+	flag.BoolVar(&cfg.Tests, "gentests", false, "also write tests of the generated code to <type>_mapconst_gen_test.go")
+	flag.BoolVar(&cfg.Fuzz, "fuzz", false, "also write a FuzzParse<type> fuzz target (Go 1.18+) to <type>_mapconst_gen_test.go")
+	flag.BoolVar(&cfg.Benchmarks, "benchmarks", false, "also write benchmarks of map, switch and parse lookups to <type>_mapconst_gen_test.go")
 }
 
 func main() {
 	log.SetFlags(0)
 	log.SetPrefix("const_list: ")
+	log.SetOutput(os.Stderr)
+	flag.Usage = usage
 
-	flag.Parse()
-	if len(config.typeNames) == 0 {
-		flag.Usage()
-		os.Exit(2)
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
+	// Without a subcommand, mapconst generates, as it always has.
+	generate(os.Args[1:])
+}
+
+// generate implements the generate subcommand, and the verify subcommand if
+// config.verify is set.
+func generate(args []string) {
+	flag.CommandLine.Parse(args)
+	if config.version {
+		fmt.Println("mapconst", gen.Version())
+		return
+	}
+	switch config.sqlDDL {
+	case "", "postgres", "mysql", "sqlite":
+	default:
+		fatalf("invalid -sqlddl=%s; must be postgres, mysql or sqlite", config.sqlDDL)
+	}
+	switch config.mod {
+	case "":
+	case "readonly", "vendor", "mod":
+		// go/build resolves packages by running the go command, which
+		// inherits the environment.
+		os.Setenv("GOFLAGS", withModFlag(os.Getenv("GOFLAGS"), config.mod))
+	default:
+		fatalf("invalid -mod=%s; must be readonly, vendor or mod", config.mod)
+	}
+	switch config.report {
+	case "", "json":
+	default:
+		fatalf("invalid -report=%s; must be json", config.report)
+	}
+	if (config.diff || config.diffOnly) && config.report != "" {
+		fatalf("-diff cannot be combined with -report, which both print to standard output")
+	}
+	// Select the platform-specific files independently of the host, so
+	// that generation is reproducible. The source importer of the type
+	// checker uses the default build context too.
+	if cfg.GOOS != "" {
+		build.Default.GOOS = cfg.GOOS
 	}
-	types := strings.Split(config.typeNames, ",")
+	if cfg.GOARCH != "" {
+		build.Default.GOARCH = cfg.GOARCH
+	}
+	if config.header != "" {
+		data, err := ioutil.ReadFile(config.header)
+		if err != nil {
+			fatalf("reading header: %s", err)
+		}
+		cfg.Header = string(data)
+	}
+	if config.headerTpl != "" {
+		data, err := ioutil.ReadFile(config.headerTpl)
+		if err != nil {
+			fatalf("reading header template: %s", err)
+		}
+		cfg.HeaderTemplate = string(data)
+	}
+	if config.templateFuncs != "" {
+		data, err := ioutil.ReadFile(config.templateFuncs)
+		if err != nil {
+			fatalf("reading template functions: %s", err)
+		}
+		if err := json.Unmarshal(data, &cfg.TemplateFuncs); err != nil {
+			fatalf("reading template functions: %s: %s", config.templateFuncs, err)
+		}
+	}
+	cfg.Args = strings.Join(withoutNeutralFlags(args), " ")
+	config.flags = withoutNeutralFlags(args[:len(args)-flag.NArg()])
+	if config.verbose {
+		cfg.Logf = infof
+	}
+	cfg.Warnf = warnf
+	cfg.Strict = config.strict
 
-	// We accept either one directory or a list of files. Which do we have?
-	args := flag.Args()
+	// We accept either directories, an import path, a list of files or
+	// "-" for a single file read from standard input. Which do we have?
+	args = flag.Args()
 	if len(args) == 0 {
 		// Default: process whole package in current directory.
 		args = []string{"."}
 	}
 
+	packages := [][]string{args}
+	if len(args) > 1 && allDirs(args) {
+		// Several directories: generate each package in turn, as if by
+		// separate runs.
+		singleOutput("several directories", "output-dir")
+		packages = nil
+		for _, dir := range args {
+			packages = append(packages, []string{dir})
+		}
+	}
+	// With -variants, each package is loaded and generated once per
+	// platform, into files that only build on it.
+	variants := []string{""}
+	if config.variants != "" {
+		singleOutput("-variants", "goos", "goarch", "sqlddl")
+		variants = strings.Split(config.variants, ",")
+		for _, v := range variants {
+			if !validVariant.MatchString(v) {
+				fatalf("invalid variant %q in -variants; must be goos or goos/goarch", v)
+			}
+		}
+	}
+	failed := false
+	for _, args := range packages {
+		for _, v := range variants {
+			if v != "" {
+				setVariant(v)
+			}
+			if !generatePackage(args) {
+				failed = true
+			}
+		}
+	}
+	if config.report != "" {
+		if err := printReport(); err != nil {
+			fatalf("writing report: %s", err)
+		}
+	}
+	if len(stale) > 0 {
+		for _, filename := range stale {
+			errorf("%s is out of date", filename)
+		}
+		os.Exit(1)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// singleOutput fails if one of the flags naming a single output file, or of
+// the extra flags, is set, as they cannot be combined with what.
+func singleOutput(what string, extra ...string) {
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "output", "ts", "openapi", "graphql", "md", "csv", "kubebuilder", "swag", "proto", "emit", "sqlddl-output":
+			fatalf("-%s cannot be combined with %s", f.Name, what)
+		}
+		for _, name := range extra {
+			if f.Name == name {
+				fatalf("-%s cannot be combined with %s", f.Name, what)
+			}
+		}
+	})
+}
+
+// validVariant matches the platforms of -variants: goos or goos/goarch.
+var validVariant = regexp.MustCompile(`^[a-z0-9]+(/[a-z0-9]+)?$`)
+
+// setVariant makes the platform of the variant, goos or goos/goarch, that
+// of the files loaded and names the output files after it, e.g.
+// status_mapconst_linux.go, so that they only build on it.
+func setVariant(variant string) {
+	goos, goarch := variant, defaultGOARCH
+	if i := strings.Index(variant, "/"); i >= 0 {
+		goos, goarch = variant[:i], variant[i+1:]
+	}
+	cfg.GOOS, cfg.GOARCH = goos, goarch
+	build.Default.GOOS, build.Default.GOARCH = goos, goarch
+	config.variant = "_" + strings.Replace(variant, "/", "_", 1)
+}
+
+// defaultGOARCH is the GOARCH of the variants that do not set it.
+var defaultGOARCH = build.Default.GOARCH
+
+// allDirs reports whether all the arguments are directories.
+func allDirs(args []string) bool {
+	for _, arg := range args {
+		if info, err := os.Stat(arg); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// generatePackage generates the types of the package of the arguments, a
+// directory, an import path, a list of files or "-", and writes the output.
+// It reports whether all the types were generated.
+func generatePackage(args []string) bool {
 	// Parse the package once.
-	dir := ""
-	var gen Generator
-	if len(args) == 1 && isDirectory(args[0]) {
-		dir = args[0]
-		gen.parsePackageDir(args[0])
-	} else {
-		dir = filepath.Dir(args[0])
-		gen.parsePackageFiles(args)
+	stdin := len(args) == 1 && args[0] == "-"
+	var g *gen.Generator
+	var err error
+	if stdin {
+		// Act as a filter: the output goes to standard output as well.
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fatalf("reading standard input: %s", err)
+		}
+		g, err = gen.LoadSource("<stdin>", src, &cfg)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		if config.output == "" && config.report == "" {
+			config.output = "stdout"
+		}
+	} else if g, err = gen.Load(args, &cfg); err != nil {
+		fatalf("%s", err)
+	}
+	typeNames := config.typeNames
+	if len(typeNames) == 0 {
+		typeNames = defaultTypes(g)
+	}
+	types, err := gen.ParseTypeSpecs(typeNames, cfg.TrimPrefix, cfg.Transform)
+	if err != nil {
+		fatalf("invalid -type: %s", err)
 	}
 
-	fmt.Fprintf(&gen.buf, headerTmpl, strings.Join(os.Args[1:], " "), gen.pkg.name)
-	// Run generate for each type.
-	for _, typeName := range types {
-		gen.generate(typeName)
+	// Decide which package the generated code belongs to. Anything other
+	// than the source package has to import it and qualify the constants.
+	// A package outside the current module, such as a third-party one in
+	// the module cache, is left untouched: the output goes to the current
+	// directory and refers to the package's exported constants.
+	outDir := g.Dir()
+	if g.ReadOnly() {
+		infof("package %s is outside the current module; writing to the current directory", g.ImportPath())
+		outDir = "."
+	}
+	if config.outputDir != "" {
+		outDir = config.outputDir
+	}
+	outPkg := config.pkgName
+	suffix := "_mapconst" + config.variant + ".go"
+	switch {
+	case config.testPkg:
+		if config.pkgName != "" || config.outputDir != "" || g.ReadOnly() {
+			fatalf("-testpackage cannot be combined with -pkg, -output-dir or a package outside the module")
+		}
+		outPkg = g.Name() + "_test"
+		suffix = "_mapconst" + config.variant + "_test.go"
+	case outPkg == "" && g.ReadOnly():
+		outPkg = packageNameOf(outDir, "")
+		if outPkg == "" {
+			fatalf("no Go package in %s to generate %s into; set -pkg", outDir, g.ImportPath())
+		}
+	case outPkg == "" && stdin && config.outputDir == "":
+		// Nothing tells where the source lives; stay in its package.
+		outPkg = g.Name()
+	case outPkg == "":
+		outPkg = packageNameOf(outDir, g.Name())
+	}
+	if outPkg == g.Name() && g.ReadOnly() {
+		fatalf("package %s cannot refer to %s of the same name", outPkg, g.ImportPath())
+	}
+	if outPkg != g.Name() {
+		if config.outputDir == "" && config.output == "" && !config.testPkg && !g.ReadOnly() {
+			fatalf("-pkg=%s requires -output-dir or -output", outPkg)
+		}
+		if err := g.Qualify(); err != nil {
+			fatalf("%s", err)
+		}
 	}
 
-	// Format the output.
-	src := gen.format()
+	// Run generate for each type. A type that fails is reported and left
+	// out, the others are still generated, unless -strict is set.
+	// With -ignore-missing, and for -variants, which may well lack some,
+	// types without constants are merely skipped.
+	var errs []error
+	for _, spec := range types {
+		err := g.Generate(spec)
+		switch {
+		case err == nil:
+		case (config.ignoreMissing || config.variant != "") && errors.Is(err, gen.ErrNoConsts):
+			warnf("%s; skipped", err)
+		default:
+			errorf("%s", err)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 && (config.strict || len(g.Types()) == 0) {
+		fatalf("%d of %d types failed; nothing written", len(errs), len(types))
+	}
+	if len(g.Types()) == 0 {
+		warnf("no type to generate; nothing written")
+		return true
+	}
+	defer func() {
+		if len(errs) > 0 {
+			errorf("%d of %d types failed", len(errs), len(types))
+		}
+	}()
 
-	// Write to file.
+	if config.output == "stdout" && config.report != "" {
+		fatalf("-report cannot be combined with output to standard output")
+	}
 	outFilename := ""
-	var err error
 	switch config.output {
 	case "stdout":
-		fmt.Println(string(src))
 	case "":
-		outFilename = path.Join(dir, strings.ToLower(types[0])+"_mapconst.go")
+		outFilename = path.Join(outDir, gen.OutputBase(types[0].Name)+suffix)
 	default:
 		outFilename = config.output
 	}
 
-	if ioutil.WriteFile(outFilename, src, 0644); err != nil {
-		log.Fatalf("writing output: %s", err)
+	// Keep the code of the other types of the file.
+	if cfg.Append {
+		if outFilename == "" {
+			fatalf("-append cannot be combined with output to standard output")
+		}
+		if existing, err := ioutil.ReadFile(outFilename); err == nil && isGenerated(existing) {
+			if err := g.Merge(existing); err != nil {
+				fatalf("merging %s: %s", outFilename, err)
+			}
+		}
+	}
+
+	// go generate runs the directive reproducing the command line in the
+	// directory of the output.
+	if cfg.EmitGenerate && outFilename != "" {
+		pkgArgs := args
+		if flag.NArg() == 0 {
+			pkgArgs = nil
+		}
+		cfg.GenerateArgs = strings.Join(directiveArgs(config.flags, pkgArgs, filepath.Dir(outFilename)), " ")
 	}
-}
 
-// isDirectory reports whether the named file is a directory.
-func isDirectory(name string) bool {
-	info, err := os.Stat(name)
+	// Format the output.
+	src, err := g.Source(outPkg)
 	if err != nil {
-		log.Fatal(err)
+		fatalf("%s", err)
 	}
-	return info.IsDir()
-}
 
-// Generator holds the state of the analysis. Primarily used to buffer
-// the output for format.Source.
-type Generator struct {
-	buf bytes.Buffer // Accumulated output.
-	pkg *Package     // Package we are scanning.
-}
+	// Write to file.
 
-func (g *Generator) Printf(format string, args ...interface{}) {
-	fmt.Fprintf(&g.buf, format, args...)
-}
+	if config.verify && outFilename == "" {
+		fatalf("verify cannot be combined with output to standard output")
+	}
+	if config.outputDir != "" && !config.verify {
+		if err := os.MkdirAll(config.outputDir, 0755); err != nil {
+			fatalf("creating output directory: %s", err)
+		}
+	}
 
-// File holds a single parsed file and associated data.
-type File struct {
-	pkg  *Package  // Package to which this file belongs.
-	file *ast.File // Parsed AST.
-	// These fields are reset for each type being generated.
-	typeName string // Name of the constant type.
-	consts   []string
-}
+	if outFilename == "" {
+		fmt.Println(string(src))
+	} else if err := writeFile(outFilename, src); err != nil {
+		fatalf("writing output: %s", err)
+	} else {
+		report.Files = append(report.Files, outFilename)
+	}
 
-type Package struct {
-	dir      string
-	name     string
-	defs     map[*ast.Ident]types.Object
-	files    []*File
-	typesPkg *types.Package
+	if cfg.Tests || cfg.Fuzz || cfg.Benchmarks {
+		src, err := g.Tests(outPkg)
+		writeOutput(path.Join(outDir, gen.OutputBase(types[0].Name)+"_mapconst_gen"+config.variant+"_test.go"), "tests", src, err)
+	}
+	emits := []struct{ filename, emitter, what string }{
+		{config.ts, "ts", "TypeScript"},
+		{config.openapi, "openapi", "OpenAPI"},
+		{config.graphql, "graphql", "GraphQL"},
+		{config.md, "md", "Markdown"},
+		{config.csv, "csv", "CSV"},
+		{config.kubebuilder, "kubebuilder", "kubebuilder markers"},
+		{config.swag, "swag", "swag"},
+		{config.proto, "proto", "proto"},
+	}
+	for _, e := range config.emits {
+		i := strings.LastIndex(e, "=")
+		emits = append(emits, struct{ filename, emitter, what string }{e[i+1:], e[:i], e[:i]})
+	}
+	for _, e := range emits {
+		if e.filename == "" {
+			continue
+		}
+		var emitter gen.Emitter = gen.ExecEmitter{Path: strings.TrimPrefix(e.emitter, "exec:")}
+		if !strings.HasPrefix(e.emitter, "exec:") {
+			emitter, _ = gen.LookupEmitter(e.emitter)
+		}
+		outputs, err := emitter.Emit(g, e.filename)
+		if err != nil {
+			fatalf("writing %s output: %s", e.what, err)
+		}
+		for _, out := range outputs {
+			writeOutput(out.Name, e.what, out.Content, nil)
+		}
+	}
+	if config.sqlDDL != "" {
+		sqlFilename := config.sqlOutput
+		if sqlFilename == "" {
+			sqlFilename = path.Join(outDir, gen.OutputBase(types[0].Name)+"_mapconst.sql")
+		}
+		src, err := g.SQLDDL(config.sqlDDL)
+		writeOutput(sqlFilename, "SQL", src, err)
+	}
+	if config.report != "" {
+		addReport(g, errs)
+	}
+	return len(errs) == 0
 }
 
-// parsePackageDir parses the package residing in the directory.
-func (g *Generator) parsePackageDir(directory string) {
-	pkg, err := build.Default.ImportDir(directory, 0)
+// defaultTypes returns the types to generate if -type is not set: those of
+// the //mapconst:types= directives of the package, or else, run by go
+// generate, the type of the const declaration following the directive.
+func defaultTypes(g *gen.Generator) string {
+	types, err := g.TypesDirective()
 	if err != nil {
-		log.Fatalf("cannot process directory %s: %s", directory, err)
-	}
-	var names []string
-	names = append(names, pkg.GoFiles...)
-	names = append(names, pkg.CgoFiles...)
-	// TODO: Need to think about constants in test files. Maybe write type_string_test.go
-	// in a separate pass? For later.
-	// names = append(names, pkg.TestGoFiles...) // These are also in the "foo" package.
-	names = append(names, pkg.SFiles...)
-	names = prefixDirectory(directory, names)
-	g.parsePackage(directory, names, nil)
+		fatalf("%s", err)
+	}
+	if types != "" {
+		infof("-type not set; generating %s as set by //mapconst:types=", types)
+		return types
+	}
+	gofile, goline := os.Getenv("GOFILE"), os.Getenv("GOLINE")
+	line, err := strconv.Atoi(goline)
+	if gofile == "" || err != nil {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if types, err = gen.ConstTypeAfter(gofile, line); err != nil {
+		fatalf("no -type: %s", err)
+	}
+	infof("-type not set; generating %s, declared after line %d of %s", types, line, gofile)
+	return types
 }
 
-// parsePackageFiles parses the package occupying the named files.
-func (g *Generator) parsePackageFiles(names []string) {
-	g.parsePackage(".", names, nil)
+// writeOutput writes the output of one of the emitters, unless it failed.
+func writeOutput(filename, what string, src []byte, err error) {
+	if err == nil {
+		err = writeFile(filename, src)
+	}
+	if err != nil {
+		fatalf("writing %s output: %s", what, err)
+	}
+	report.Files = append(report.Files, filename)
 }
 
-// prefixDirectory places the directory name on the beginning of each name in the list.
-func prefixDirectory(directory string, names []string) []string {
-	if directory == "." {
-		return names
-	}
-	ret := make([]string, len(names))
-	for i, name := range names {
-		ret[i] = filepath.Join(directory, name)
+// neutralFlags are the flags that do not affect the output: those of -diff
+// and of logging.
+var neutralFlags = map[string]bool{"diff": true, "diff-only": true, "v": true, "q": true, "quiet": true}
+
+// withoutNeutralFlags returns the arguments without the neutralFlags: the
+// header shows the command a plain run writes, and its hash does not change
+// with the logging.
+func withoutNeutralFlags(args []string) []string {
+	var rest []string
+	for _, arg := range args {
+		name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+		if strings.HasPrefix(arg, "-") && neutralFlags[name] {
+			continue
+		}
+		rest = append(rest, arg)
 	}
-	return ret
+	return rest
+}
+
+// pathFlags are the flags naming files or directories, relative to the
+// current directory unless absolute.
+var pathFlags = map[string]bool{
+	"output":          true,
+	"output-dir":      true,
+	"template-funcs":  true,
+	"header":          true,
+	"header-template": true,
+	"ts":              true,
+	"openapi":         true,
+	"md":              true,
+	"kubebuilder":     true,
+	"swag":            true,
+	"csv":             true,
+	"graphql":         true,
+	"proto":           true,
+	"sqlddl-output":   true,
+	"emit":            true,
 }
 
-// parsePackage analyzes the single package constructed from the named files.
-// If text is non-nil, it is a string to be used instead of the content of the file,
-// to be used for testing. parsePackage exits if there is an error.
-func (g *Generator) parsePackage(directory string, names []string, text interface{}) {
-	var files []*File
-	var astFiles []*ast.File
-	g.pkg = new(Package)
-	fs := token.NewFileSet()
-	for _, name := range names {
-		if !strings.HasSuffix(name, ".go") {
+// directiveArgs returns the flags and the package arguments of the command
+// line as they must be to run in dir: relative file names and directories
+// are made relative to dir, and local packages, such as ./internal/status,
+// are named by their directory relative to dir.
+func directiveArgs(flags, args []string, dir string) []string {
+	var rest []string
+	for i := 0; i < len(flags); i++ {
+		arg := flags[i]
+		name := strings.TrimLeft(arg, "-")
+		value := ""
+		hasValue := false
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+		if !pathFlags[name] {
+			rest = append(rest, arg)
 			continue
 		}
-		parsedFile, err := parser.ParseFile(fs, name, text, 0)
-		if err != nil {
-			log.Fatalf("parsing package: %s: %s", name, err)
+		if !hasValue {
+			// The value is the next argument.
+			rest = append(rest, arg)
+			if i++; i == len(flags) {
+				break
+			}
+			rest = append(rest, directivePath(name, flags[i], dir))
+			continue
 		}
-		astFiles = append(astFiles, parsedFile)
-		files = append(files, &File{
-			file: parsedFile,
-			pkg:  g.pkg,
-		})
+		rest = append(rest, arg[:len(arg)-len(value)]+directivePath(name, value, dir))
 	}
-	if len(astFiles) == 0 {
-		log.Fatalf("%s: no buildable Go files", directory)
+	for _, arg := range args {
+		if build.IsLocalImport(arg) || strings.HasSuffix(arg, ".go") || filepath.IsAbs(arg) {
+			arg = relativePath(arg, dir)
+			if !filepath.IsAbs(arg) && !build.IsLocalImport(arg) && !strings.HasSuffix(arg, ".go") {
+				arg = "./" + arg
+			}
+		}
+		rest = append(rest, arg)
 	}
-	g.pkg.name = astFiles[0].Name.Name
-	g.pkg.files = files
-	g.pkg.dir = directory
+	return rest
 }
 
-func (g *Generator) generate(typeName string) {
-	consts := make([]string, 0, 100)
-	for _, file := range g.pkg.files {
-		// Set the state for this run of the walker.
-		file.typeName = typeName
-		file.consts = make([]string, 0)
-		if file.file != nil {
-			ast.Inspect(file.file, file.genDecl)
-			consts = append(consts, file.consts...)
-		}
+// directivePath returns the value of the path flag as it must be to run in
+// dir. The value of -emit is emitter=filename, of which exec:program names
+// a file too if it holds a slash.
+func directivePath(name, value, dir string) string {
+	if name != "emit" {
+		return relativePath(value, dir)
+	}
+	i := strings.LastIndex(value, "=")
+	emitter, filename := value[:i], value[i+1:]
+	if program := strings.TrimPrefix(emitter, "exec:"); program != emitter && strings.Contains(program, "/") {
+		emitter = "exec:" + relativePath(program, dir)
 	}
+	return emitter + "=" + relativePath(filename, dir)
+}
 
-	if len(consts) == 0 {
-		log.Fatalf("no const defined for type %s", typeName)
+// relativePath returns the path, relative to the current directory unless
+// absolute, relative to dir instead, in slash-separated form. An absolute
+// path, or one that cannot be made relative, is returned as is.
+func relativePath(name, dir string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return name
+	}
+	absDir := dir
+	if !filepath.IsAbs(absDir) {
+		absDir = filepath.Join(wd, dir)
 	}
+	rel, err := filepath.Rel(absDir, filepath.Join(wd, name))
+	if err != nil {
+		return name
+	}
+	return filepath.ToSlash(rel)
+}
 
-	tpl := template.Must(template.New("mapConstTpl").Parse(mapConstTpl))
-	tpl.Execute(&g.buf, &mapConstData{
-		Type:   typeName,
-		Consts: consts,
-	})
+// withModFlag returns the GOFLAGS value with its -mod flag, if any, replaced
+// by -mod=mode.
+func withModFlag(goflags, mode string) string {
+	flags := []string{"-mod=" + mode}
+	for _, f := range strings.Fields(goflags) {
+		if !strings.HasPrefix(f, "-mod=") && !strings.HasPrefix(f, "--mod=") {
+			flags = append(flags, f)
+		}
+	}
+	return strings.Join(flags, " ")
 }
 
-// format returns the gofmt-ed contents of the Generator's buffer.
-func (g *Generator) format() []byte {
-	src, err := format.Source(g.buf.Bytes())
+// packageNameOf returns the name of the Go package in directory, or def if
+// the directory holds no buildable package and is the source directory.
+// A new directory is named after its last path element.
+func packageNameOf(directory, def string) string {
+	pkg, err := build.Default.ImportDir(directory, 0)
+	if err == nil {
+		return pkg.Name
+	}
+	if config.outputDir == "" {
+		return def
+	}
+	abs, err := filepath.Abs(directory)
 	if err != nil {
-		// Should never happen, but can arise when developing this code.
-		// The user can compile the output to see the error.
-		log.Printf("warning: internal error: invalid Go generated: %s", err)
-		log.Print("warning: compile the package to analyze the error")
-		return g.buf.Bytes()
+		fatalf("%s", err)
 	}
-	return src
+	return strings.Replace(filepath.Base(abs), "-", "_", -1)
 }
 
-// genDecl processes one declaration clause.
-func (f *File) genDecl(node ast.Node) bool {
-	decl, ok := node.(*ast.GenDecl)
-	if !ok || decl.Tok != token.CONST {
-		// We only care about const declarations.
-		return true
+// emitsFlag is the -emit flag, which accumulates its values. Each names a
+// registered emitter, or one run by exec:, and the file it writes.
+type emitsFlag struct {
+	values *[]string
+}
+
+func (f emitsFlag) String() string {
+	if f.values == nil {
+		return ""
 	}
-	// The name of the type of the constants we are declaring.
-	// Can change if this is a multi-element declaration.
-	typ := ""
-	// Loop over the elements of the declaration. Each element is a ValueSpec:
-	// a list of names possibly followed by a type, possibly followed by values.
-	// If the type and value are both missing, we carry down the type (and value,
-	// but the "go/types" package takes care of that).
-	for _, spec := range decl.Specs {
-		vspec := spec.(*ast.ValueSpec) // Guaranteed to succeed as this is CONST.
-		if vspec.Type == nil && len(vspec.Values) > 0 {
-			// "X = 1". With no type but a value, the constant is untyped.
-			// Skip this vspec and reset the remembered type.
-			typ = ""
-			continue
-		}
-		if vspec.Type != nil {
-			// "X T". We have a type. Remember it.
-			ident, ok := vspec.Type.(*ast.Ident)
-			if !ok {
-				continue
-			}
-			typ = ident.Name
-		}
-		if typ == f.typeName {
-			f.consts = append(f.consts, vspec.Names[0].Name)
+	return strings.Join(*f.values, " ")
+}
+
+func (f emitsFlag) Set(s string) error {
+	i := strings.LastIndex(s, "=")
+	if i <= 0 || i == len(s)-1 {
+		return fmt.Errorf("%q is not of the form emitter=filename", s)
+	}
+	if name := s[:i]; !strings.HasPrefix(name, "exec:") {
+		if _, ok := gen.LookupEmitter(name); !ok {
+			return fmt.Errorf("unknown emitter %q; must be one of %s, or exec:program", name, strings.Join(gen.EmitterNames(), ", "))
 		}
 	}
-	return false
+	*f.values = append(*f.values, s)
+	return nil
+}
+
+// typesFlag is the -type flag. Repeating it accumulates the values,
+// separated by ";".
+type typesFlag struct {
+	value *string
+}
+
+func (f typesFlag) String() string {
+	if f.value == nil {
+		return ""
+	}
+	return *f.value
+}
+
+func (f typesFlag) Set(s string) error {
+	if *f.value != "" {
+		*f.value += ";"
+	}
+	*f.value += s
+	return nil
 }
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the test binary as mapconst for the tests running it with
+// MAPCONST_TEST_MAIN set, through mapconst, and as the program of an exec
+// emitter with MAPCONST_TEST_EMITTER set, through emitterScript.
+func TestMain(m *testing.M) {
+	switch {
+	case os.Getenv("MAPCONST_TEST_EMITTER") != "":
+		var input struct{ Generated string }
+		if err := json.NewDecoder(os.Stdin).Decode(&input); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("# %s\n", input.Generated)
+		os.Exit(0)
+	case os.Getenv("MAPCONST_TEST_MAIN") != "":
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// emitterScript returns a script running the test binary as the program of
+// an exec emitter, writing the "Code generated" line as a # comment.
+func emitterScript(t *testing.T) string {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), "emitter")
+	src := fmt.Sprintf("#!/bin/sh\nMAPCONST_TEST_EMITTER=1 exec %q \"$@\"\n", os.Args[0])
+	if err := ioutil.WriteFile(script, []byte(src), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+// mapconst runs mapconst with the arguments in dir, failing the test if it
+// fails.
+func mapconst(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "MAPCONST_TEST_MAIN=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("mapconst %q: %s\n%s", args, err, out)
+	}
+}
+
+// statusSrc declares a Status type with constants.
+const statusSrc = `package status
+
+type Status int
+
+const (
+	Active Status = iota
+	Inactive
+)
+`
+
+// writePackage writes the package of the source to a new directory of a
+// module and returns the directory.
+func writePackage(t *testing.T, src string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":    "module example.com/status\n\ngo 1.18\n",
+		"status.go": src,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// TestRegenerateAndClean checks that mapconst recognizes the files it
+// writes as generated: a second run replaces them and clean removes them.
+func TestRegenerateAndClean(t *testing.T) {
+	for _, output := range []struct{ flag, file string }{
+		{"-md", "status.md"},
+		{"-csv", "status.csv"},
+		{"-csv", "status.tsv"},
+		{"-emit=exec:" + emitterScript(t), "status.txt"},
+	} {
+		t.Run(output.file, func(t *testing.T) {
+			dir := writePackage(t, statusSrc)
+			filename := filepath.Join(dir, output.file)
+			args := []string{"-type=Status", output.flag + "=" + output.file, "."}
+			mapconst(t, dir, args...)
+			if _, err := os.Stat(filename); err != nil {
+				t.Fatal(err)
+			}
+			mapconst(t, dir, args...)
+			mapconst(t, dir, "clean", ".")
+			if _, err := os.Stat(filename); !os.IsNotExist(err) {
+				t.Errorf("clean left %s: %v", output.file, err)
+			}
+		})
+	}
+}
+
+// TestVerifyHandEdit checks that verify fails once the generated code is
+// edited by hand, though its input hash still matches.
+func TestVerifyHandEdit(t *testing.T) {
+	dir := writePackage(t, statusSrc)
+	mapconst(t, dir, "-type=Status", ".")
+	mapconst(t, dir, "verify", "-type=Status", ".")
+
+	filename := filepath.Join(dir, "status_mapconst.go")
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(src), `"Inactive"`, `"Disabled"`, 1)
+	if edited == string(src) {
+		t.Fatalf("no key Inactive in:\n%s", src)
+	}
+	if err := ioutil.WriteFile(filename, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(os.Args[0], "verify", "-type=Status", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "MAPCONST_TEST_MAIN=1")
+	if out, err := cmd.CombinedOutput(); err == nil {
+		t.Errorf("verify passed the hand-edited file:\n%s", out)
+	}
+	mapconst(t, dir, "-type=Status", ".")
+	if src, _ := ioutil.ReadFile(filename); string(src) == edited {
+		t.Error("generate left the hand-edited file untouched")
+	}
+}
+
+// TestEmitGenerate checks that go generate reproduces the output of
+// -emit-generate run on a package in a subdirectory, with the path flags
+// relative to the current directory.
+func TestEmitGenerate(t *testing.T) {
+	dir := t.TempDir()
+	pkgDir := filepath.Join(dir, "internal", "status")
+	for _, d := range []string{pkgDir, filepath.Join(dir, "docs")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		"go.mod":                    "module example.com/status\n\ngo 1.18\n",
+		"internal/status/status.go": statusSrc,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mapconst(t, dir, "-type=Status", "-emit-generate", "-md", "docs/status.md", "./internal/status")
+	goFile, mdFile := filepath.Join(pkgDir, "status_mapconst.go"), filepath.Join(dir, "docs", "status.md")
+	src, err := ioutil.ReadFile(goFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	directive := "//go:generate mapconst -type=Status -emit-generate -md ../../docs/status.md .\n"
+	if !strings.Contains(string(src), directive) {
+		t.Fatalf("no %q in:\n%s", directive, src)
+	}
+
+	// Add a constant and remove the Markdown output for go generate to
+	// bring back. go generate finds mapconst in PATH.
+	src = []byte(strings.Replace(statusSrc, "\tInactive\n", "\tInactive\n\tPending\n", 1))
+	if err := ioutil.WriteFile(filepath.Join(pkgDir, "status.go"), src, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(mdFile); err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\nMAPCONST_TEST_MAIN=1 exec %q \"$@\"\n", os.Args[0])
+	if err := ioutil.WriteFile(filepath.Join(bin, "mapconst"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	goCmd(t, dir, "generate", "./...")
+	for _, filename := range []string{goFile, mdFile} {
+		if src, err := ioutil.ReadFile(filename); err != nil {
+			t.Error(err)
+		} else if !strings.Contains(string(src), "Pending") {
+			t.Errorf("go generate did not add Pending to %s:\n%s", filename, src)
+		}
+	}
+}
+
+// TestDirectiveArgs checks that the paths of the command line are made
+// relative to the directory of the output.
+func TestDirectiveArgs(t *testing.T) {
+	for _, tt := range []struct {
+		flags, args []string
+		dir         string
+		want        string
+	}{
+		{nil, nil, "internal/status", ""},
+		{[]string{"-type=Status", "-json"}, []string{"./internal/status"}, "internal/status", "-type=Status -json ."},
+		{nil, []string{"./internal/status"}, "gen/status", "../../internal/status"},
+		{nil, []string{"internal/status/status.go", "internal/status/kind.go"}, "internal/status", "status.go kind.go"},
+		{nil, []string{"example.com/status"}, "internal/status", "example.com/status"},
+		{nil, []string{"."}, "internal/status", "../.."},
+		{nil, []string{"./internal"}, ".", "./internal"},
+		{[]string{"-output-dir=gen", "-md", "docs/status.md"}, nil, "gen", "-output-dir=. -md ../docs/status.md"},
+		{[]string{"--output=/tmp/status.go", "-header=LICENSE"}, nil, "internal/status", "--output=/tmp/status.go -header=../../LICENSE"},
+		{[]string{"-emit=ts=web/status.ts", "-emit=exec:./tools/emit=status.txt", "-emit=exec:emit=status.txt"}, nil, "internal", "-emit=ts=../web/status.ts -emit=exec:../tools/emit=../status.txt -emit=exec:emit=../status.txt"},
+	} {
+		if got := strings.Join(directiveArgs(tt.flags, tt.args, tt.dir), " "); got != tt.want {
+			t.Errorf("directiveArgs(%q, %q, %s) = %q, want %q", tt.flags, tt.args, tt.dir, got, tt.want)
+		}
+	}
+}
+
+// TestAssert checks that the assertions of -assert compile, for negative
+// values too.
+func TestAssert(t *testing.T) {
+	dir := writePackage(t, `package status
+
+type Status int
+
+const (
+	Unknown Status = -1
+	Active  Status = iota
+	Inactive
+	Removed Status = -10
+)
+`)
+	mapconst(t, dir, "-type=Status", "-assert", ".")
+	goCmd(t, dir, "vet", ".")
+}
+
+// TestTestGen checks that the code of -testgen compiles and draws the
+// constants in property-based tests.
+func TestTestGen(t *testing.T) {
+	dir := writePackage(t, statusSrc)
+	mapconst(t, dir, "-type=Status", "-testgen", ".")
+	test := `package status
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+)
+
+func TestQuick(t *testing.T) {
+	valid := func(s Status) bool { return s == Active || s == Inactive }
+	if err := quick.Check(valid, nil); err != nil {
+		t.Error(err)
+	}
+	if s := RandomStatus(rand.New(rand.NewSource(1))); !valid(s) {
+		t.Errorf("RandomStatus returned %d", s)
+	}
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "status_test.go"), []byte(test), 0644); err != nil {
+		t.Fatal(err)
+	}
+	goCmd(t, dir, "test", ".")
+}
+
+// goCmd runs the go command with the arguments in dir, failing the test if
+// it fails.
+func goCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go %s: %s\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// TestCleanAppend checks that clean -type leaves a file shared by -append
+// unless all its types are listed.
+func TestCleanAppend(t *testing.T) {
+	dir := writePackage(t, statusSrc+`
+type Kind int
+
+const (
+	Small Kind = iota
+	Large
+)
+`)
+	filename := filepath.Join(dir, "enums_mapconst.go")
+	mapconst(t, dir, "-type=Status", "-append", "-output=enums_mapconst.go", ".")
+	mapconst(t, dir, "-type=Kind", "-append", "-output=enums_mapconst.go", ".")
+	for _, types := range []string{"Kind", "Status"} {
+		mapconst(t, dir, "clean", "-type="+types, ".")
+		if _, err := os.Stat(filename); err != nil {
+			t.Fatalf("clean -type=%s: %v", types, err)
+		}
+	}
+	mapconst(t, dir, "clean", "-type=Kind,Status", ".")
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("clean -type=Kind,Status left enums_mapconst.go: %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/empirefox/mapconst/gen"
+)
+
+// stale lists the output files that the verify subcommand found missing or
+// out of date.
+var stale []string
+
+// writeFile replaces the named file with data atomically: data goes to a
+// temporary file in the same directory, which is renamed into place once
+// complete and removed on failure. Concurrent runs and readers thus never
+// see a partially written file. Unless -force is set, it refuses to replace
+// a file that mapconst did not generate. A file that is up to date, as
+// upToDate has it, is left untouched, keeping its mtime. With -diff, it
+// first prints the diff of the changes to standard output; with -diff-only,
+// it writes nothing. The verify subcommand writes nothing either; it
+// records the files that would change in stale.
+func writeFile(filename string, data []byte) (err error) {
+	if config.diff || config.diffOnly {
+		existing, err := ioutil.ReadFile(filename)
+		oldName := filename
+		if os.IsNotExist(err) {
+			oldName = "/dev/null"
+		} else if err != nil {
+			return err
+		}
+		if !upToDate(existing, data) {
+			fmt.Print(unifiedDiff(oldName, filename, existing, data))
+		}
+		if config.diffOnly {
+			return nil
+		}
+	}
+	if config.verify {
+		if existing, err := ioutil.ReadFile(filename); err != nil || !upToDate(existing, data) {
+			stale = append(stale, filename)
+		}
+		return nil
+	}
+	if existing, err := ioutil.ReadFile(filename); err == nil {
+		if !config.force && !isGenerated(existing) {
+			return fmt.Errorf("%s exists and was not generated by mapconst; use -force to overwrite it", filename)
+		}
+		if upToDate(existing, data) {
+			infof("%s is up to date", filename)
+			return nil
+		}
+	}
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := ioutil.TempFile(dir, "."+base+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(0644); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// upToDate reports whether the existing file content is data or differs
+// from it in the header only, such as by a timestamp, having been generated
+// from the same inputs according to their input hash lines. Code edited by
+// hand after the header makes the file out of date.
+func upToDate(existing, data []byte) bool {
+	if bytes.Equal(existing, data) {
+		return true
+	}
+	hash := inputHash(data)
+	return hash != "" && hash == inputHash(existing) && bytes.Equal(body(existing), body(data))
+}
+
+// body returns the Go source from its package clause on, or nil if it has
+// none.
+func body(src []byte) []byte {
+	for i := 0; i < len(src); {
+		if bytes.HasPrefix(src[i:], []byte("package ")) {
+			return src[i:]
+		}
+		n := bytes.IndexByte(src[i:], '\n')
+		if n < 0 {
+			break
+		}
+		i += n + 1
+	}
+	return nil
+}
+
+// inputHash returns the input hash of generated Go source, or "".
+func inputHash(src []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, gen.InputHashPrefix) {
+			return strings.TrimPrefix(line, gen.InputHashPrefix)
+		}
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+	}
+	return ""
+}
+
+// isGenerated reports whether the file content starts with comments, in any
+// of the languages mapconst writes, holding its "Code generated" line. The
+// comments of Markdown are HTML ones, between <!-- and -->.
+func isGenerated(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	html := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !html && strings.HasPrefix(line, "<!--") {
+			line = strings.TrimSpace(strings.TrimPrefix(line, "<!--"))
+			html = true
+		}
+		if html {
+			if i := strings.Index(line, "-->"); i >= 0 {
+				line = strings.TrimSpace(line[:i])
+				html = false
+			}
+			if strings.HasPrefix(line, `Code generated by "mapconst`) {
+				return true
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		comment := false
+		for _, marker := range []string{"//", "#", "--"} {
+			if strings.HasPrefix(line, marker) {
+				line = strings.TrimSpace(strings.TrimPrefix(line, marker))
+				comment = true
+				break
+			}
+		}
+		if !comment {
+			return false
+		}
+		// Older versions escaped the quotes.
+		if strings.HasPrefix(line, `Code generated by "mapconst`) || strings.HasPrefix(line, `Code generated by \"mapconst`) {
+			return true
+		}
+	}
+	return false
+}
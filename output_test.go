@@ -0,0 +1,74 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	const generated = "// Code generated by \"mapconst -type=Status\"; DO NOT EDIT.\n\npackage status\n"
+	for _, tt := range []struct {
+		name     string
+		existing string
+		force    bool
+		wantErr  string
+	}{
+		{"new", "", false, ""},
+		{"generated", generated, false, ""},
+		{"hand-written", "package status\n\nfunc main() {}\n", false, "was not generated by mapconst; use -force"},
+		{"hand-written forced", "package status\n\nfunc main() {}\n", true, ""},
+		{"header after code", "package status\n\n// Code generated by \"mapconst\"; DO NOT EDIT.\n", false, "was not generated by mapconst"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(force bool) { config.force = force }(config.force)
+			config.force = tt.force
+			filename := filepath.Join(t.TempDir(), "status_mapconst.go")
+			if tt.existing != "" {
+				if err := ioutil.WriteFile(filename, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			data := []byte(strings.Replace(generated, "Status", "Status,Kind", 1))
+			err := writeFile(filename, data)
+			content, _ := ioutil.ReadFile(filename)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatal(err)
+			case tt.wantErr == "" && string(content) != string(data):
+				t.Errorf("file holds %q, want %q", content, data)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("error %v, want one containing %q", err, tt.wantErr)
+			case tt.wantErr != "" && string(content) != tt.existing:
+				t.Errorf("refused write changed the file to %q", content)
+			}
+		})
+	}
+}
+
+func TestUpToDate(t *testing.T) {
+	const (
+		header = "// Code generated by \"mapconst -type=Status\"; DO NOT EDIT.\n"
+		hash   = "// mapconst input hash: 0123\n"
+		code   = "\npackage status\n\nvar StatusNameToValue = map[string]Status{}\n"
+	)
+	data := header + hash + code
+	for _, tt := range []struct {
+		name     string
+		existing string
+		want     bool
+	}{
+		{"same", data, true},
+		{"other header", "// Generated at 2026-10-15T00:00:00Z.\n" + header + hash + code, true},
+		{"other hash", header + "// mapconst input hash: 4567\n" + code, false},
+		{"no hash", header + code, false},
+		{"hand-edited", header + hash + strings.Replace(code, "{}", "{\"Active\": 0}", 1), false},
+		{"code appended", data + "\nfunc init() {}\n", false},
+		{"no package clause", header + hash, false},
+	} {
+		if got := upToDate([]byte(tt.existing), []byte(data)); got != tt.want {
+			t.Errorf("%s: upToDate = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Package registry collects the enum types generated by mapconst with
+// -register, so that a program can list and look up every enum it contains
+// without reflection, e.g. for introspection endpoints.
+//
+// The generated code registers each type in an init function:
+//
+//	func init() {
+//		registry.Register(&registry.Enum{
+//			Type:   "example.com/shop/status.Status",
+//			Names:  []string{"Active", "Inactive"},
+//			Values: []interface{}{Active, Inactive},
+//		})
+//	}
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Enum is a registered enum type.
+type Enum struct {
+	Type   string        // The import path of the package and the name of the type, e.g. "example.com/shop/status.Status".
+	Names  []string      // The names of the constants, in declaration order.
+	Values []interface{} // The constants, in the order of Names.
+}
+
+// Lookup returns the constant of e named name, and whether there is one.
+func (e *Enum) Lookup(name string) (interface{}, bool) {
+	for i, n := range e.Names {
+		if n == name {
+			return e.Values[i], true
+		}
+	}
+	return nil, false
+}
+
+var (
+	mu    sync.RWMutex
+	enums = make(map[string]*Enum)
+)
+
+// Register adds the enum type to the registry. It panics if a type of the
+// same name is registered already, or if e has not a value per name.
+func Register(e *Enum) {
+	if len(e.Names) != len(e.Values) {
+		panic(fmt.Sprintf("registry: %s has %d names but %d values", e.Type, len(e.Names), len(e.Values)))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := enums[e.Type]; ok {
+		panic("registry: " + e.Type + " registered twice")
+	}
+	enums[e.Type] = e
+}
+
+// Lookup returns the registered enum type named typ, as in Enum.Type.
+func Lookup(typ string) (*Enum, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	e, ok := enums[typ]
+	return e, ok
+}
+
+// Enums returns the registered enum types, sorted by Type.
+func Enums() []*Enum {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]*Enum, 0, len(enums))
+	for _, e := range enums {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Type < list[j].Type })
+	return list
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/empirefox/mapconst/gen"
+)
+
+// report is what -report=json prints: the types generated, their constants
+// and the files written.
+var report struct {
+	Types  []reportType `json:"types"`
+	Files  []string     `json:"files"`
+	Errors []string     `json:"errors,omitempty"`
+}
+
+type reportType struct {
+	Name   string        `json:"name"`
+	Consts []reportConst `json:"constants"`
+}
+
+type reportConst struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"` // The value as a literal, e.g. 200 or us-east-1; empty if unresolved.
+	Key     string `json:"key"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// addReport adds the generated types of g and their errors to the report.
+func addReport(g *gen.Generator, errs []error) {
+	for _, name := range g.Types() {
+		t := reportType{Name: name, Consts: []reportConst{}}
+		for _, c := range g.Consts(name) {
+			t.Consts = append(t.Consts, reportConst{Name: c.Name, Value: c.Lit, Key: c.Key, Comment: c.Doc})
+		}
+		report.Types = append(report.Types, t)
+	}
+	for _, err := range errs {
+		report.Errors = append(report.Errors, err.Error())
+	}
+}
+
+// printReport prints the report as JSON to standard output.
+func printReport() error {
+	if report.Types == nil {
+		report.Types = []reportType{}
+	}
+	if report.Files == nil {
+		report.Files = []string{}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&report)
+}